@@ -0,0 +1,141 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BookmarkAliasPrefix marks a bookmark file that points to another bookmark instead of a reference
+const BookmarkAliasPrefix = "@bookmark:"
+
+// BookmarkExpiresPrefix starts the line of a bookmark file holding its expiry time (RFC 3339)
+const BookmarkExpiresPrefix = "expires: "
+
+// GetBookmarksDirectory returns the directory bookmarks are stored in: GIT_TOOLS_BOOKMARK_DIR, then
+// git-tools.bookmark.dir, then .git/bookmarks. Relative paths are relative to the root of the work tree.
+func GetBookmarksDirectory(cfg *Config) (string, error) {
+	dir := os.Getenv("GIT_TOOLS_BOOKMARK_DIR")
+	if dir == "" {
+		dir = cfg.BookmarkDir
+	}
+	if dir == "" {
+		gitDir, err := GetGitDirectory()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(gitDir, "bookmarks"), nil
+	}
+
+	if !filepath.IsAbs(dir) {
+		root, err := GetTopLevelDirectory()
+		if err != nil {
+			// Bare repositories have no work tree, use the git directory instead
+			if root, err = GetGitDirectory(); err != nil {
+				return "", err
+			}
+		}
+		dir = filepath.Join(root, dir)
+	}
+	return dir, nil
+}
+
+// ReadBookmarkFile returns the first line of a bookmark file, which is either a reference or an alias
+func ReadBookmarkFile(bookmarksDir, name string) (string, error) {
+	lines, err := readBookmarkLines(bookmarksDir, name)
+	if err != nil {
+		return "", err
+	}
+	return lines[0], nil
+}
+
+// GetBookmarkExpiry returns when a bookmark created with a TTL expires. The bool is false for
+// bookmarks without one.
+func GetBookmarkExpiry(bookmarksDir, name string) (time.Time, bool, error) {
+	lines, err := readBookmarkLines(bookmarksDir, name)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	// Lines after the reference hold metadata
+	for _, line := range lines[1:] {
+		if value, found := strings.CutPrefix(line, BookmarkExpiresPrefix); found {
+			expires, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return time.Time{}, false, fmt.Errorf("bookmark '%s' has an invalid expiry time '%s'", name, value)
+			}
+			return expires, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// readBookmarkLines returns the trimmed lines of a bookmark file, the first one being the reference
+func readBookmarkLines(bookmarksDir, name string) ([]string, error) {
+	bookmarkFile := filepath.Join(bookmarksDir, name)
+
+	if _, err := os.Stat(bookmarkFile); os.IsNotExist(err) {
+		return nil, fmt.Errorf("bookmark '%s' does not exist", name)
+	}
+
+	content, err := os.ReadFile(bookmarkFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmark: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines, nil
+}
+
+// GetBookmarkReference returns the reference of a bookmark, following an alias to its target bookmark
+func GetBookmarkReference(bookmarksDir, name string) (string, error) {
+	content, err := ReadBookmarkFile(bookmarksDir, name)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(content, BookmarkAliasPrefix) {
+		return content, nil
+	}
+
+	// Only one level of indirection is followed, so aliases can't form cycles
+	target := strings.TrimPrefix(content, BookmarkAliasPrefix)
+	reference, err := ReadBookmarkFile(bookmarksDir, target)
+	if err != nil {
+		return "", fmt.Errorf("alias '%s' points to a missing bookmark: %v", name, err)
+	}
+	if strings.HasPrefix(reference, BookmarkAliasPrefix) {
+		return "", fmt.Errorf("alias '%s' points to '%s', which is also an alias", name, target)
+	}
+	return reference, nil
+}
+
+// ResolveBookmarkExpression returns the reference of a bookmark, optionally followed by a
+// ~ or ^ suffix applied to it (e.g. base~1)
+func ResolveBookmarkExpression(bookmarksDir, expression string) (string, error) {
+	if reference, err := GetBookmarkReference(bookmarksDir, expression); err == nil {
+		return reference, nil
+	}
+
+	index := strings.IndexAny(expression, "~^")
+	if index <= 0 {
+		return GetBookmarkReference(bookmarksDir, expression)
+	}
+
+	name, suffix := expression[:index], expression[index:]
+	reference, err := GetBookmarkReference(bookmarksDir, name)
+	if err != nil {
+		return "", err
+	}
+
+	reference += suffix
+	if !GitRefExists(reference) {
+		return "", fmt.Errorf("'%s' expands to '%s', which does not resolve to a commit", expression, reference)
+	}
+	return reference, nil
+}
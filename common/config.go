@@ -0,0 +1,89 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Config holds the per-repo defaults read from the git-tools.* git config keys.
+// Command line flags always take precedence over these values.
+type Config struct {
+	// Remote is the default remote (git-tools.remote)
+	Remote string
+	// BackupPrefix is the ref prefix backups are created under (git-tools.backup-prefix)
+	BackupPrefix string
+	// AutoBackup makes tools create a backup before modifying history (git-tools.auto-backup)
+	AutoBackup bool
+	// BookmarkDir is the directory bookmarks are stored in instead of .git/bookmarks (git-tools.bookmark.dir)
+	BookmarkDir string
+	// BackupPostHook is a shell command run after each backup (git-tools.backup.post-hook)
+	BackupPostHook string
+}
+
+// LoadConfig reads the git-tools.* keys from git config, using defaults for missing keys
+func LoadConfig() (*Config, error) {
+	cfg := &Config{
+		Remote:       "origin",
+		BackupPrefix: "backups",
+		AutoBackup:   false,
+	}
+
+	remote, err := getConfigValue("git-tools.remote", "")
+	if err != nil {
+		return nil, err
+	}
+	if remote != "" {
+		cfg.Remote = remote
+	}
+
+	prefix, err := getConfigValue("git-tools.backup-prefix", "")
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		cfg.BackupPrefix = prefix
+	}
+
+	autoBackup, err := getConfigValue("git-tools.auto-backup", "bool")
+	if err != nil {
+		return nil, err
+	}
+	cfg.AutoBackup = autoBackup == "true"
+
+	cfg.BookmarkDir, err = getConfigValue("git-tools.bookmark.dir", "path")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.BackupPostHook, err = getConfigValue("git-tools.backup.post-hook", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// getConfigValue reads a git config key, returning an empty string if it is not set
+func getConfigValue(key, valueType string) (string, error) {
+	args := []string{"config"}
+	if valueType != "" {
+		args = append(args, "--type="+valueType)
+	}
+	args = append(args, "--get", key)
+
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1 means the key is not set
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("invalid value for %s: %s", key, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"testing"
+
+	"git-tools/common/testutil"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	testutil.NewRepo(t)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Remote != "origin" || cfg.BackupPrefix != "backups" || cfg.AutoBackup {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+}
+
+func TestLoadConfigGitConfigOverridesDefaults(t *testing.T) {
+	testutil.NewRepo(t)
+	testutil.Git(t, "config", "git-tools.remote", "upstream")
+	testutil.Git(t, "config", "git-tools.backup-prefix", "/saved/")
+	testutil.Git(t, "config", "git-tools.auto-backup", "yes")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Remote != "upstream" {
+		t.Errorf("Remote = %q, want upstream", cfg.Remote)
+	}
+	if cfg.BackupPrefix != "saved" {
+		t.Errorf("BackupPrefix = %q, want saved", cfg.BackupPrefix)
+	}
+	if !cfg.AutoBackup {
+		t.Errorf("AutoBackup = false, want true")
+	}
+}
+
+func TestLoadConfigEmptyValuesKeepDefaults(t *testing.T) {
+	testutil.NewRepo(t)
+	testutil.Git(t, "config", "git-tools.remote", "")
+	testutil.Git(t, "config", "git-tools.backup-prefix", "/")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Remote != "origin" || cfg.BackupPrefix != "backups" {
+		t.Errorf("empty values replaced the defaults: %+v", cfg)
+	}
+}
+
+func TestLoadConfigRejectsInvalidBool(t *testing.T) {
+	testutil.NewRepo(t)
+	testutil.Git(t, "config", "git-tools.auto-backup", "sometimes")
+
+	if _, err := LoadConfig(); err == nil {
+		t.Errorf("LoadConfig accepted git-tools.auto-backup=sometimes")
+	}
+}
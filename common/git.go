@@ -6,20 +6,51 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 // isGitRepository checks if the current directory is a git repository
+// Git itself is asked rather than looking for a .git directory, so GIT_DIR,
+// GIT_WORK_TREE and subdirectories of the work tree are handled correctly.
 func IsGitRepository() bool {
-	if _, err := os.Stat(".git"); err == nil {
-		return true
-	}
-
 	cmd := exec.Command("git", "rev-parse", "--git-dir")
 	cmd.Stderr = nil
 	return cmd.Run() == nil
 }
 
+// GetTopLevelDirectory returns the root of the work tree
+func GetTopLevelDirectory() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsBareRepository checks if the repository has no work tree
+func IsBareRepository() bool {
+	cmd := exec.Command("git", "rev-parse", "--is-bare-repository")
+	output, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// IsDetachedHead checks if HEAD points directly to a commit instead of a branch
+func IsDetachedHead() (bool, error) {
+	cmd := exec.Command("git", "symbolic-ref", "-q", "HEAD")
+	err := cmd.Run()
+	if err == nil {
+		return false, nil
+	}
+	// Exit code 1 means HEAD is not a symbolic ref
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, err
+}
+
 // getGitDirectory returns the path to the .git directory
 func GetGitDirectory() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--git-dir")
@@ -30,6 +61,20 @@ func GetGitDirectory() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetToolsDirectory returns the path to the .git/git-tools directory, creating it if needed
+func GetToolsDirectory() (string, error) {
+	gitDir, err := GetGitDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	toolsDir := filepath.Join(gitDir, "git-tools")
+	if err := os.MkdirAll(toolsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create git-tools directory: %v", err)
+	}
+	return toolsDir, nil
+}
+
 // gitRefExists checks if a git reference exists
 func GitRefExists(ref string) bool {
 	cmd := exec.Command("git", "rev-parse", "--verify", ref)
@@ -68,22 +113,39 @@ func CreateBranch(branchName, fromRef string) error {
 	return cmd.Run()
 }
 
-// runGitBackup runs the git backup command
-func RunGitBackup() error {
+// BackupOperationEnv is the environment variable through which tools tell git-backup which
+// operation the backup is taken for, so it is added to the backup name
+const BackupOperationEnv = "GIT_TOOLS_BACKUP_OP"
+
+// runGitBackup runs the git backup command, labelling the backup with the operation
+func RunGitBackup(operation string) error {
 	cmd := exec.Command("git-backup")
+	cmd.Env = append(os.Environ(), BackupOperationEnv+"="+operation)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-// runGitBackupWithRef runs the git backup command for the specified reference
-func RunGitBackupWithRef(ref string) error {
+// runGitBackupWithRef runs the git backup command for the specified reference, labelling the backup with the operation
+func RunGitBackupWithRef(ref, operation string) error {
 	cmd := exec.Command("git-backup", ref)
+	cmd.Env = append(os.Environ(), BackupOperationEnv+"="+operation)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// ResolveCommit gets the hash of the commit a reference points to, peeling annotated tags.
+// Unlike GetCommitHash, it fails for anything that isn't a commit, including option-like names.
+func ResolveCommit(ref string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a commit", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // getCommitHash gets the commit hash for a given reference
 func GetCommitHash(ref string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", ref)
@@ -94,11 +156,158 @@ func GetCommitHash(ref string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// ResolveUnambiguousRef gets the commit hash for a reference, failing if git warns that the
+// name is ambiguous (e.g. a branch and a tag sharing it) instead of silently picking one
+func ResolveUnambiguousRef(ref string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", ref+"^{commit}")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("reference '%s' does not exist", ref)
+	}
+	if strings.Contains(stderr.String(), "is ambiguous") {
+		return "", fmt.Errorf("reference '%s' is ambiguous, use a full name like refs/heads/%s or refs/tags/%s", ref, ref, ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FullRefName returns the fully-qualified name of a reference, e.g. refs/heads/main for main.
+// Like ResolveUnambiguousRef, it fails instead of picking one of several refs sharing the name.
+func FullRefName(ref string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--symbolic-full-name", ref)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("reference '%s' does not exist", ref)
+	}
+	if strings.Contains(stderr.String(), "is ambiguous") {
+		return "", fmt.Errorf("reference '%s' is ambiguous, use a full name like refs/heads/%s or refs/tags/%s", ref, ref, ref)
+	}
+
+	fullName := strings.TrimSpace(string(output))
+	if fullName == "" {
+		return "", fmt.Errorf("'%s' is not a named reference", ref)
+	}
+	return fullName, nil
+}
+
+// GetUpstream gets the upstream of a branch (e.g. origin/main), or of the current branch if branch is empty
+func GetUpstream(branch string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", branch+"@{upstream}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no upstream configured")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetPushTarget gets where git push would push a branch (e.g. fork/main), or the current branch if
+// branch is empty. It differs from the upstream with push.default or a separate push remote.
+func GetPushTarget(branch string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", branch+"@{push}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no push target configured")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SetUpstream sets the upstream the branch tracks
+func SetUpstream(branch, upstream string) error {
+	cmd := exec.Command("git", "branch", "--set-upstream-to="+upstream, branch)
+	return cmd.Run()
+}
+
+// RevParse runs git rev-parse with the given arguments and returns its output.
+// On failure, git's exit code is returned along with its error message.
+func RevParse(args ...string) (string, int, error) {
+	cmd := exec.Command("git", append([]string{"rev-parse"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		exitCode := 1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return string(output), exitCode, fmt.Errorf("%s", message)
+	}
+	return string(output), 0, nil
+}
+
+// GetShortCommitHash gets the abbreviated commit hash for a given reference
+func GetShortCommitHash(ref string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--short", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// MergeBase gets the best common ancestor of two references
+func MergeBase(a, b string) (string, error) {
+	cmd := exec.Command("git", "merge-base", a, b)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", fmt.Errorf("no common ancestor between '%s' and '%s'", a, b)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ForkPoint returns the commit where HEAD forked from upstream, using the upstream's reflog
+// to see past rewrites of upstream. It falls back to the merge base when the reflog doesn't tell.
+func ForkPoint(upstream string) (string, error) {
+	cmd := exec.Command("git", "merge-base", "--fork-point", upstream, "HEAD")
+	output, err := cmd.Output()
+	if err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+	return MergeBase(upstream, "HEAD")
+}
+
+// IsAncestor checks if the ancestor reference is an ancestor of (or equal to) the descendant reference
+func IsAncestor(ancestor, descendant string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, descendant)
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, err
+}
+
 func Checkout(commit string) error {
 	cmd := exec.Command("git", "checkout", commit)
 	return cmd.Run()
 }
 
+// CheckoutNewBranch creates a branch at a reference and checks it out, git doesn't leave the
+// branch behind if the checkout fails
+func CheckoutNewBranch(branchName, fromRef string) error {
+	cmd := exec.Command("git", "checkout", "-b", branchName, fromRef)
+	return cmd.Run()
+}
+
+// ShowStat prints a one-line summary and diffstat of a commit
+func ShowStat(ref string) error {
+	cmd := exec.Command("git", "show", "--stat", "--oneline", ref)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 // moveBranch moves a branch to point to a new reference
 func MoveBranch(branchName, newRef string) error {
 	cmd := exec.Command("git", "branch", "-f", branchName, newRef)
@@ -121,78 +330,305 @@ func IsCherryPickInProgress() bool {
 	return false
 }
 
+// GetCherryPickHead returns the commit of the cherry-pick in progress, or "" if there is none
+func GetCherryPickHead() string {
+	hash, err := GetCommitHash("CHERRY_PICK_HEAD")
+	if err != nil {
+		return ""
+	}
+	return hash
+}
+
+// GetSequencerTodo returns the commits git still has to pick, read from .git/sequencer/todo.
+// The todo only exists for multi-commit cherry-picks, and starts with the commit being picked.
+func GetSequencerTodo() ([]string, error) {
+	gitDir, err := GetGitDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(gitDir, "sequencer", "todo"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []string
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		hash, err := GetCommitHash(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve '%s' from the sequencer todo: %v", fields[1], err)
+		}
+		commits = append(commits, hash)
+	}
+	return commits, nil
+}
+
+// IsMergeInProgress checks if a merge operation is in progress
+func IsMergeInProgress() bool {
+	gitDir, err := GetGitDirectory()
+	if err != nil {
+		return false
+	}
+
+	if _, err := os.Stat(filepath.Join(gitDir, "MERGE_HEAD")); err == nil {
+		return true
+	}
+
+	return false
+}
+
+// IsRebaseInProgress checks if a rebase operation is in progress
+func IsRebaseInProgress() bool {
+	gitDir, err := GetGitDirectory()
+	if err != nil {
+		return false
+	}
+
+	// Interactive and merge-based rebases use rebase-merge, am-based ones use rebase-apply
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(gitDir, dir)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AbortRebase aborts a rebase operation
+func AbortRebase() error {
+	cmd := exec.Command("git", "rebase", "--abort")
+	return cmd.Run()
+}
+
+// AutosquashRebase rebases the commits since upstream, folding fixup! commits into their
+// targets without opening the todo list
+func AutosquashRebase(upstream string) error {
+	cmd := exec.Command("git", "rebase", "--interactive", "--autosquash", upstream)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// IsRevertInProgress checks if a revert operation is in progress
+func IsRevertInProgress() bool {
+	gitDir, err := GetGitDirectory()
+	if err != nil {
+		return false
+	}
+
+	if _, err := os.Stat(filepath.Join(gitDir, "REVERT_HEAD")); err == nil {
+		return true
+	}
+
+	return false
+}
+
+// Operation is a git operation that can stop halfway, waiting for conflicts to be resolved
+type Operation string
+
+const (
+	OperationNone       Operation = "none"
+	OperationRebase     Operation = "rebase"
+	OperationMerge      Operation = "merge"
+	OperationCherryPick Operation = "cherry-pick"
+	OperationRevert     Operation = "revert"
+)
+
+// InProgressOperation returns the git operation in progress, or OperationNone. A rebase comes
+// first, since the picks and merges it replays leave their own markers while it stops.
+func InProgressOperation() Operation {
+	switch {
+	case IsRebaseInProgress():
+		return OperationRebase
+	case IsMergeInProgress():
+		return OperationMerge
+	case IsCherryPickInProgress():
+		return OperationCherryPick
+	case IsRevertInProgress():
+		return OperationRevert
+	}
+	return OperationNone
+}
+
 // hasUncommittedChanges checks if there are uncommitted changes
 func HasUncommittedChanges() bool {
-	cmd := exec.Command("git", "status", "--porcelain")
-	output, err := cmd.Output()
-	return err == nil && strings.TrimSpace(string(output)) != ""
+	count, err := CountUncommittedChanges()
+	return err == nil && count > 0
+}
+
+// CountUncommittedChanges counts the files with staged, unstaged or untracked changes
+func CountUncommittedChanges() (int, error) {
+	return countStatusEntries(func(indexStatus, workingTreeStatus byte) bool {
+		return true
+	})
 }
 
 // hasUnstagedChanges checks if there are unstaged changes
 func HasUnstagedChanges() (bool, error) {
+	count, err := CountUnstagedChanges()
+	return count > 0, err
+}
+
+// CountUnstagedChanges counts the files with unstaged changes, including untracked files
+func CountUnstagedChanges() (int, error) {
+	return countStatusEntries(func(indexStatus, workingTreeStatus byte) bool {
+		// Check if the working tree status (second character) indicates changes
+		if workingTreeStatus == 'M' || workingTreeStatus == 'D' || workingTreeStatus == 'T' {
+			return true
+		}
+		// Check for untracked files (marked as ??)
+		return indexStatus == '?' && workingTreeStatus == '?'
+	})
+}
+
+// CountUntrackedFiles counts the untracked files that aren't ignored
+func CountUntrackedFiles() (int, error) {
+	return countStatusEntries(func(indexStatus, workingTreeStatus byte) bool {
+		return indexStatus == '?' && workingTreeStatus == '?'
+	})
+}
+
+// hasStagedChanges checks if there are staged changes
+func HasStagedChanges() (bool, error) {
+	count, err := CountStagedChanges()
+	return count > 0, err
+}
+
+// CountStagedChanges counts the files with staged changes
+func CountStagedChanges() (int, error) {
+	return countStatusEntries(func(indexStatus, workingTreeStatus byte) bool {
+		// Check if the index status (first character) indicates staged changes
+		return indexStatus == 'M' || indexStatus == 'A' || indexStatus == 'D' ||
+			indexStatus == 'R' || indexStatus == 'C' || indexStatus == 'T'
+	})
+}
+
+// countStatusEntries counts the git status entries for which match returns true
+func countStatusEntries(match func(indexStatus, workingTreeStatus byte) bool) (int, error) {
 	cmd := exec.Command("git", "status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	count := 0
+	// Only trim the trailing newline, a leading space is the index status of the first entry
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
 	for _, line := range lines {
-		if len(line) >= 2 {
-			// Check if the working tree status (second character) indicates changes
-			workingTreeStatus := line[1]
-			if workingTreeStatus == 'M' || workingTreeStatus == 'D' || workingTreeStatus == 'T' {
-				return true, nil
-			}
-			// Check for untracked files (marked as ??)
-			if line[0] == '?' && line[1] == '?' {
-				return true, nil
-			}
+		if len(line) >= 2 && match(line[0], line[1]) {
+			count++
 		}
 	}
-	return false, nil
+	return count, nil
 }
 
-// hasStagedChanges checks if there are staged changes
-func HasStagedChanges() (bool, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
+// StatusSummary is the state of the branch and of the work tree, as used by prompts
+type StatusSummary struct {
+	Branch    string `json:"branch"`
+	Upstream  string `json:"upstream"`
+	Ahead     int    `json:"ahead"`
+	Behind    int    `json:"behind"`
+	Staged    int    `json:"staged"`
+	Unstaged  int    `json:"unstaged"`
+	Conflicts int    `json:"conflicts"`
+	Dirty     bool   `json:"dirty"`
+}
+
+// GetStatusSummary reads the whole summary from a single git status call. Files are counted
+// the same way as CountStagedChanges and CountUnstagedChanges. Branch is empty when HEAD is detached.
+func GetStatusSummary() (*StatusSummary, error) {
+	cmd := exec.Command("git", "status", "--porcelain=v2", "--branch")
 	output, err := cmd.Output()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		if len(line) >= 2 {
-			// Check if the index status (first character) indicates staged changes
-			indexStatus := line[0]
-			if indexStatus == 'M' || indexStatus == 'A' || indexStatus == 'D' ||
-				indexStatus == 'R' || indexStatus == 'C' || indexStatus == 'T' {
-				return true, nil
+	summary := &StatusSummary{}
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "#":
+			switch fields[1] {
+			case "branch.head":
+				if len(fields) > 2 && fields[2] != "(detached)" {
+					summary.Branch = fields[2]
+				}
+			case "branch.upstream":
+				if len(fields) > 2 {
+					summary.Upstream = fields[2]
+				}
+			case "branch.ab":
+				if len(fields) > 3 {
+					fmt.Sscanf(fields[2], "+%d", &summary.Ahead)
+					fmt.Sscanf(fields[3], "-%d", &summary.Behind)
+				}
+			}
+		case "1", "2":
+			// Changed entries start with the XY status of the index and of the work tree
+			indexStatus, workingTreeStatus := fields[1][0], fields[1][1]
+			if strings.IndexByte("MADRCT", indexStatus) >= 0 {
+				summary.Staged++
 			}
+			if strings.IndexByte("MDT", workingTreeStatus) >= 0 {
+				summary.Unstaged++
+			}
+			summary.Dirty = true
+		case "u":
+			summary.Conflicts++
+			summary.Dirty = true
+		case "?":
+			summary.Unstaged++
+			summary.Dirty = true
 		}
 	}
-	return false, nil
+	return summary, nil
 }
 
 // hasConflicts checks if there are merge conflicts
 func HasConflicts() bool {
-	cmd := exec.Command("git", "status", "--porcelain")
+	files, err := ConflictedFiles()
+	return err == nil && len(files) > 0
+}
+
+// ConflictedFiles gets the paths of the files with merge conflicts
+func ConflictedFiles() ([]string, error) {
+	// -z keeps paths unquoted, entries are NUL-separated
+	cmd := exec.Command("git", "status", "--porcelain", "-z")
 	output, err := cmd.Output()
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
+	var files []string
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		line := entries[i]
+		// Renames and copies are followed by an entry holding the original path
+		if len(line) > 0 && (line[0] == 'R' || line[0] == 'C') {
+			i++
+			continue
+		}
+		// Only the unmerged pairs, AD is a staged addition deleted from the work tree
 		if strings.HasPrefix(line, "UU ") || strings.HasPrefix(line, "AA ") ||
 			strings.HasPrefix(line, "DD ") || strings.HasPrefix(line, "AU ") ||
 			strings.HasPrefix(line, "UD ") || strings.HasPrefix(line, "UA ") ||
-			strings.HasPrefix(line, "DU ") || strings.HasPrefix(line, "AD ") {
-			return true
+			strings.HasPrefix(line, "DU ") {
+			files = append(files, line[3:])
 		}
 	}
-	return false
+	return files, nil
 }
 
 // continueCherryPick continues a cherry-pick operation
@@ -201,48 +637,298 @@ func ContinueCherryPick() error {
 	return cmd.Run()
 }
 
+// ContinueCherryPickWithoutEditing continues a cherry-pick operation, keeping the commit message as it is
+func ContinueCherryPickWithoutEditing() error {
+	cmd := exec.Command("git", "-c", "core.editor=true", "cherry-pick", "--continue")
+	return cmd.Run()
+}
+
+// ResolveConflict resolves a conflicted file by taking its "ours" or "theirs" version and staging it
+func ResolveConflict(path, side string) error {
+	if err := exec.Command("git", "checkout", "--"+side, "--", path).Run(); err != nil {
+		return err
+	}
+	return exec.Command("git", "add", "--", path).Run()
+}
+
 // abortCherryPick aborts a cherry-pick operation
 func AbortCherryPick() error {
 	cmd := exec.Command("git", "cherry-pick", "--abort")
 	return cmd.Run()
 }
 
-// cherryPickCommit cherry-picks a specific commit
-func CherryPickCommit(commit string) error {
-	cmd := exec.Command("git", "cherry-pick", commit)
+// RebaseOnto replays the commits of branch since upstream onto newBase, keeping merge commits
+func RebaseOnto(newBase, upstream, branch string) error {
+	cmd := exec.Command("git", "rebase", "--rebase-merges", "--onto", newBase, upstream, branch)
 	return cmd.Run()
 }
 
-// getCommitMessage gets the commit message for a given commit
-func GetCommitMessage(commit string) (string, error) {
-	cmd := exec.Command("git", "log", "--format=%s", "-n", "1", commit)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+// ContinueRebase continues a rebase operation, keeping the commit messages as they are
+func ContinueRebase() error {
+	cmd := exec.Command("git", "-c", "core.editor=true", "rebase", "--continue")
+	return cmd.Run()
+}
+
+// AddDetachedWorktree creates a linked worktree at path with commit checked out as a detached HEAD
+func AddDetachedWorktree(path, commit string) error {
+	cmd := exec.Command("git", "worktree", "add", "--quiet", "--detach", path, commit)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
 	}
-	return strings.TrimSpace(string(output)), nil
+	return nil
 }
 
-// createStagedDiff creates a diff file of staged changes
-func CreateStagedDiff(filename string) error {
-	cmd := exec.Command("git", "diff", "--staged")
-	output, err := cmd.Output()
+// RemoveWorktree removes a linked worktree, discarding any changes left in it
+func RemoveWorktree(path string) error {
+	cmd := exec.Command("git", "worktree", "remove", "--force", path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// TrialCherryPick cherry-picks a commit in the worktree at path and returns the files that
+// conflicted, and whether the commit turned out empty. A conflicting commit is still committed
+// with its own version of the conflicted files, so the next trial starts from the same content
+// it would after a resolution favoring the commit.
+func TrialCherryPick(path, commit string) ([]string, bool, error) {
+	if err := exec.Command("git", "-C", path, "cherry-pick", "--allow-empty", commit).Run(); err == nil {
+		return nil, false, nil
+	}
+
+	output, err := exec.Command("git", "-C", path, "diff", "--name-only", "--diff-filter=U").Output()
 	if err != nil {
-		return err
+		return nil, false, err
+	}
+	var conflicts []string
+	for _, file := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if file != "" {
+			conflicts = append(conflicts, file)
+		}
 	}
 
-	return os.WriteFile(filename, output, 0644)
+	if len(conflicts) == 0 {
+		// Nothing conflicted, so the changes are already in the parent
+		if err := exec.Command("git", "-C", path, "cherry-pick", "--skip").Run(); err != nil {
+			return nil, false, fmt.Errorf("failed to cherry-pick %s", commit)
+		}
+		return nil, true, nil
+	}
+
+	for _, file := range conflicts {
+		// Files the commit deleted have no "theirs" version and keep their conflict markers
+		exec.Command("git", "-C", path, "checkout", "--theirs", "--", file).Run()
+	}
+	if err := exec.Command("git", "-C", path, "add", "--all").Run(); err != nil {
+		return nil, false, err
+	}
+	if err := exec.Command("git", "-C", path, "commit", "--quiet", "--no-verify", "--allow-empty", "--reuse-message", commit).Run(); err != nil {
+		return nil, false, err
+	}
+	return conflicts, false, nil
 }
 
-// amendCommit amends the previous commit with staged changes
-func AmendCommit() error {
-	cmd := exec.Command("git", "commit", "--amend", "--no-edit")
+// cherryPickCommit cherry-picks a specific commit
+func CherryPickCommit(commit string) error {
+	cmd := exec.Command("git", "cherry-pick", commit)
 	return cmd.Run()
 }
 
-// applyReverseDiff applies a diff file in reverse
-func ApplyReverseDiff(filename string) error {
-	cmd := exec.Command("git", "apply", "--reverse", filename)
+// getCommitMessage gets the commit message for a given commit
+func GetCommitMessage(commit string) (string, error) {
+	return CommitField(commit, "%s")
+}
+
+// GetCommitBody gets the body of a commit message, without the subject line
+func GetCommitBody(commit string) (string, error) {
+	return CommitField(commit, "%b")
+}
+
+// GetCommitFullMessage gets the subject and body of a commit message
+func GetCommitFullMessage(commit string) (string, error) {
+	return CommitField(commit, "%B")
+}
+
+// GetCommitSubjects gets the subject of each commit in a single git call, keyed by the commit as given
+func GetCommitSubjects(commits []string) (map[string]string, error) {
+	subjects := make(map[string]string, len(commits))
+	if len(commits) == 0 {
+		return subjects, nil
+	}
+
+	args := append([]string{"log", "--no-walk=unsorted", "--format=%H%x00%s"}, commits...)
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	byHash := make(map[string]string, len(lines))
+	for _, line := range lines {
+		hash, subject, _ := strings.Cut(line, "\x00")
+		byHash[hash] = subject
+	}
+
+	// Commits may be given abbreviated, so match them by prefix against the full hashes
+	for _, commit := range commits {
+		if subject, ok := byHash[commit]; ok {
+			subjects[commit] = subject
+			continue
+		}
+		for hash, subject := range byHash {
+			if strings.HasPrefix(hash, commit) {
+				subjects[commit] = subject
+				break
+			}
+		}
+	}
+	return subjects, nil
+}
+
+// CommitField formats a commit with a git log format string, e.g. '%an <%ae>' for its author
+func CommitField(ref, format string) (string, error) {
+	cmd := exec.Command("git", "log", "--format="+format, "-n", "1", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// createStagedDiff creates a diff file of staged changes
+func CreateStagedDiff(filename string) error {
+	// --binary makes binary changes reversible with git apply
+	cmd := exec.Command("git", "diff", "--staged", "--binary")
+	output, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, output, 0644)
+}
+
+// WriteIndexTree writes the index as a tree object and returns its hash
+func WriteIndexTree() (string, error) {
+	cmd := exec.Command("git", "write-tree")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SnapshotWorkTree returns a tree of the tracked files as they are in the work tree, without
+// touching the index or the stash list
+func SnapshotWorkTree() (string, error) {
+	cmd := exec.Command("git", "stash", "create")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	// Nothing to stash means the work tree matches HEAD
+	stash := strings.TrimSpace(string(output))
+	if stash == "" {
+		stash = "HEAD"
+	}
+	return GetCommitHash(stash + "^{tree}")
+}
+
+// RestoreTrees sets the tracked files of the work tree to one tree and the index to another
+func RestoreTrees(workTree, index string) error {
+	cmd := exec.Command("git", "read-tree", "-u", "--reset", workTree)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore the work tree: %s", strings.TrimSpace(string(output)))
+	}
+	cmd = exec.Command("git", "read-tree", index)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore the index: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// StagedFiles gets the paths of the staged files, with both paths of renamed files
+func StagedFiles() ([]string, error) {
+	cmd := exec.Command("git", "diff", "--staged", "--name-only", "--no-renames", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// ShellQuote quotes a value so it can be safely evaluated by a POSIX shell
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// StagedBinaryFiles gets the paths of the staged files git considers binary
+func StagedBinaryFiles() ([]string, error) {
+	// Binary files are reported with '-' for the added and deleted line counts
+	cmd := exec.Command("git", "diff", "--staged", "--numstat", "--no-renames", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range strings.Split(string(output), "\x00") {
+		fields := strings.SplitN(entry, "\t", 3)
+		if len(fields) == 3 && fields[0] == "-" && fields[1] == "-" {
+			files = append(files, fields[2])
+		}
+	}
+	return files, nil
+}
+
+// amendCommit amends the previous commit with staged changes. The message is kept unless a new
+// one is given, or edit is set to open the editor on it.
+func AmendCommit(message string, edit bool) error {
+	args := []string{"commit", "--amend"}
+	if message != "" {
+		args = append(args, "-m", message)
+	} else if !edit {
+		args = append(args, "--no-edit")
+	}
+
+	cmd := exec.Command("git", args...)
+	if edit {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	return cmd.Run()
+}
+
+// AmendCommitResetAuthor amends the previous commit, making the current user its author
+func AmendCommitResetAuthor() error {
+	cmd := exec.Command("git", "commit", "--amend", "--no-edit", "--reset-author", "--allow-empty")
+	return cmd.Run()
+}
+
+// SoftReset moves HEAD to the reference, keeping the changes staged
+func SoftReset(ref string) error {
+	cmd := exec.Command("git", "reset", "--soft", ref)
+	return cmd.Run()
+}
+
+// HardReset moves HEAD and the current branch to the reference, discarding the changes in the work tree
+func HardReset(ref string) error {
+	cmd := exec.Command("git", "reset", "--hard", ref)
+	return cmd.Run()
+}
+
+// applyReverseDiff applies a diff file in reverse
+func ApplyReverseDiff(filename string) error {
+	cmd := exec.Command("git", "apply", "--reverse", filename)
 	return cmd.Run()
 }
 
@@ -261,6 +947,37 @@ func FetchBranch(remote string, branch string, shallow bool) error {
 	return cmd.Run()
 }
 
+// FetchRemote fetches all the branches of a remote
+func FetchRemote(remote string) error {
+	cmd := exec.Command("git", "fetch", remote)
+	return cmd.Run()
+}
+
+// GetBranchRemote returns the remote a local branch tracks, or "." if it tracks a local branch
+func GetBranchRemote(branch string) (string, error) {
+	cmd := exec.Command("git", "config", "--get", "branch."+branch+".remote")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("branch '%s' has no remote configured", branch)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// FastForwardBranch moves a local branch to ref, refusing anything but a fast-forward.
+// git also refuses to update a branch checked out in a work tree this way.
+func FastForwardBranch(branch, ref string) error {
+	cmd := exec.Command("git", "fetch", "--quiet", ".", ref+":refs/heads/"+branch)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%s", message)
+		}
+		return err
+	}
+	return nil
+}
+
 // createCommit creates a new commit with an optional message
 func CreateCommit(message string) error {
 	if message != "" {
@@ -305,6 +1022,21 @@ func GetAllBranches() ([]string, error) {
 	return branches, nil
 }
 
+// GetLocalBranches gets the names of the local branches
+func GetLocalBranches() ([]string, error) {
+	cmd := exec.Command("git", "branch", "--format=%(refname:short)")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(branches) == 1 && branches[0] == "" {
+		return []string{}, nil
+	}
+	return branches, nil
+}
+
 // Get the main branch on a remote
 func GetRemoteMainBranch(remote string) (string, error) {
 	ref := remote + "/HEAD"
@@ -324,6 +1056,51 @@ func GetRemoteMainBranch(remote string) (string, error) {
 	return strings.Join(parts[1:], "/"), nil
 }
 
+// HasMergeCommits checks if a range of commits contains any merge commit
+func HasMergeCommits(revRange string) (bool, error) {
+	cmd := exec.Command("git", "rev-list", "--merges", "--count", revRange)
+	output, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(output)) != "0", nil
+}
+
+// LocalDefaultBranch finds the local default branch without looking at any remote: the
+// init.defaultBranch branch if it exists, then main, then master
+func LocalDefaultBranch() (string, error) {
+	var candidates []string
+	cmd := exec.Command("git", "config", "--get", "init.defaultBranch")
+	if output, err := cmd.Output(); err == nil {
+		if name := strings.TrimSpace(string(output)); name != "" {
+			candidates = append(candidates, name)
+		}
+	}
+	candidates = append(candidates, "main", "master")
+
+	for _, candidate := range candidates {
+		if IsBranch(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no local default branch found (tried %s)", strings.Join(candidates, ", "))
+}
+
+// AheadBehind counts the commits of head that are not in base (ahead) and the commits of base that are not in head (behind)
+func AheadBehind(base, head string) (int, int, error) {
+	cmd := exec.Command("git", "rev-list", "--left-right", "--count", base+"..."+head)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var behind, ahead int
+	if _, err := fmt.Sscanf(string(output), "%d\t%d", &behind, &ahead); err != nil {
+		return 0, 0, fmt.Errorf("unexpected git output: %q", strings.TrimSpace(string(output)))
+	}
+	return ahead, behind, nil
+}
+
 // getCommitRange gets a range of commits using git rev-list
 func GetCommitRange(revRange string, reverse bool) ([]string, error) {
 	args := []string{"rev-list"}
@@ -345,12 +1122,324 @@ func GetCommitRange(revRange string, reverse bool) ([]string, error) {
 	return commits, nil
 }
 
+// ChangedFiles gets the paths of the files changed between base and head
+func ChangedFiles(base, head string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "-z", base+"..."+head)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// ListStashes gets the commit hashes of the stash entries, most recent first
+func ListStashes() ([]string, error) {
+	cmd := exec.Command("git", "stash", "list", "--format=%H")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	stashes := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(stashes) == 1 && stashes[0] == "" {
+		return []string{}, nil
+	}
+	return stashes, nil
+}
+
+// StashPush stashes the changes to tracked files. It returns false when there was nothing to stash.
+func StashPush(message string) (bool, error) {
+	before, err := ListStashes()
+	if err != nil {
+		return false, err
+	}
+	if output, err := exec.Command("git", "stash", "push", "--message", message).CombinedOutput(); err != nil {
+		return false, fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	after, err := ListStashes()
+	if err != nil {
+		return false, err
+	}
+	return len(after) > len(before), nil
+}
+
+// StashPop applies the most recent stash entry and drops it. On conflicts git keeps the entry.
+func StashPop() error {
+	cmd := exec.Command("git", "stash", "pop")
+	return cmd.Run()
+}
+
+// ListTags gets the tags matching a glob pattern (all tags if the pattern is empty)
+func ListTags(pattern string) ([]string, error) {
+	args := []string{"tag", "--list"}
+	if pattern != "" {
+		args = append(args, pattern)
+	}
+
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(tags) == 1 && tags[0] == "" {
+		return []string{}, nil
+	}
+	return tags, nil
+}
+
+// GetLastTag returns the most recent tag reachable from ref
+func GetLastTag(ref string) (string, error) {
+	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no tag is reachable from '%s'", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// DescribeOptions configures Describe
+type DescribeOptions struct {
+	// Ref is the commit to describe, HEAD if empty
+	Ref string
+	// Dirty appends -dirty when the work tree has uncommitted changes, only for HEAD
+	Dirty bool
+}
+
+// Describe names a commit after the last tag reachable from it, e.g. v1.2.3-5-gabc1234, falling
+// back to the abbreviated hash when there is no tag
+func Describe(opts DescribeOptions) (string, error) {
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	cmd := exec.Command("git", "describe", "--tags", "--long", "--always", ref)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("cannot describe '%s': %s", ref, strings.TrimSpace(stderr.String()))
+	}
+
+	version := strings.TrimSpace(string(output))
+	if opts.Dirty && HasUncommittedChanges() {
+		version += "-dirty"
+	}
+	return version, nil
+}
+
+// LogEntry describes a commit of a log listing
+type LogEntry struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Email   string `json:"email"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+}
+
+// GetLog returns the commits of a range, newest first
+func GetLog(revRange string) ([]LogEntry, error) {
+	cmd := exec.Command("git", "log", "--format=%H%x1f%an%x1f%ae%x1f%aI%x1f%s", revRange, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		entries = append(entries, LogEntry{Hash: fields[0], Author: fields[1], Email: fields[2], Date: fields[3], Subject: fields[4]})
+	}
+	return entries, nil
+}
+
+// FormatLog returns the commits of a range, newest first, formatted with a git log format
+func FormatLog(revRange, format string) (string, error) {
+	cmd := exec.Command("git", "log", "--format="+format, revRange, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// GetBranchesContaining gets the local branches whose history contains the commit
+func GetBranchesContaining(commit string) ([]string, error) {
+	cmd := exec.Command("git", "branch", "--contains", commit, "--format=%(refname:short)")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(branches) == 1 && branches[0] == "" {
+		return []string{}, nil
+	}
+	return branches, nil
+}
+
+// GetBranchesPointingAt gets the local branches whose tip is the commit
+func GetBranchesPointingAt(commit string) ([]string, error) {
+	cmd := exec.Command("git", "branch", "--points-at", commit, "--format=%(refname:short)")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(branches) == 1 && branches[0] == "" {
+		return []string{}, nil
+	}
+	return branches, nil
+}
+
+// IsTag checks if a reference is a tag
+func IsTag(ref string) bool {
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/tags/"+ref)
+	return cmd.Run() == nil
+}
+
+// RefType is the kind of object a reference designates
+type RefType int
+
+const (
+	RefUnknown RefType = iota
+	RefBranch
+	RefRemoteBranch
+	RefTag
+	RefCommit
+)
+
+// String returns the name of the reference type, as printed by git get ref-type
+func (t RefType) String() string {
+	switch t {
+	case RefBranch:
+		return "branch"
+	case RefRemoteBranch:
+		return "remote-branch"
+	case RefTag:
+		return "tag"
+	case RefCommit:
+		return "commit"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifyRef tells whether a reference is a branch (including a symbolic ref to one, like HEAD),
+// a remote branch, a tag, or another expression resolving to a commit. Tags are checked before
+// branches, like git does when a name is ambiguous.
+func ClassifyRef(ref string) RefType {
+	switch {
+	case IsTag(ref):
+		return RefTag
+	case IsBranch(ref), GetBranchName(ref) != "":
+		return RefBranch
+	case exec.Command("git", "show-ref", "--verify", "--quiet", "refs/remotes/"+ref).Run() == nil:
+		return RefRemoteBranch
+	case GitRefExists(ref + "^{commit}"):
+		return RefCommit
+	default:
+		return RefUnknown
+	}
+}
+
+// CreateTag creates a tag at ref. An annotated tag without a message opens the editor.
+func CreateTag(name, ref string, annotated bool, message string) error {
+	args := []string{"tag"}
+	if annotated || message != "" {
+		args = append(args, "-a")
+	}
+	if message != "" {
+		args = append(args, "-m", message)
+	}
+	args = append(args, name, ref)
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// IsValidRefName checks that git accepts the full reference name, e.g. refs/tags/v1.0
+func IsValidRefName(ref string) bool {
+	cmd := exec.Command("git", "check-ref-format", ref)
+	return cmd.Run() == nil
+}
+
+// NextNameNumber returns the number to suffix base with so it doesn't collide with the existing
+// names: one more than the highest <base>-<n> taken, base itself counting as 0
+func NextNameNumber(existing []string, base string) int {
+	numberRegex := regexp.MustCompile(fmt.Sprintf(`^%s-(\d+)$`, regexp.QuoteMeta(base)))
+
+	highest := -1
+	for _, name := range existing {
+		name = strings.TrimSpace(name)
+		number := -1
+		if name == base {
+			number = 0
+		} else if matches := numberRegex.FindStringSubmatch(name); matches != nil {
+			number, _ = strconv.Atoi(matches[1])
+		}
+		if number > highest {
+			highest = number
+		}
+	}
+
+	if highest < 0 {
+		return 1
+	}
+	return highest + 1
+}
+
+// GetUserHandle returns a short name for the current user, usable in reference names: the local
+// part of the committer email, falling back to the USER environment variable
+func GetUserHandle() string {
+	// git var honors GIT_COMMITTER_EMAIL as well as user.email, the ident being "Name <email> date"
+	cmd := exec.Command("git", "var", "GIT_COMMITTER_IDENT")
+	if output, err := cmd.Output(); err == nil {
+		ident := string(output)
+		start, end := strings.Index(ident, "<"), strings.Index(ident, ">")
+		if start >= 0 && end > start {
+			if handle, _, _ := strings.Cut(ident[start+1:end], "@"); handle != "" {
+				return handle
+			}
+		}
+	}
+	return os.Getenv("USER")
+}
+
 // isBranch checks if a reference is a local branch
 func IsBranch(ref string) bool {
 	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+ref)
 	return cmd.Run() == nil
 }
 
+// CreateBranchRef creates refs/heads/<refName> at a commit, failing instead of overwriting
+// the branch if it already exists
+func CreateBranchRef(refName, commitHash string) error {
+	cmd := exec.Command("git", "update-ref", "refs/heads/"+refName, commitHash, "")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // writeRefFile writes a commit hash directly to a git ref file
 func WriteRefFile(refName, commitHash string) error {
 	gitDir, err := GetGitDirectory()
@@ -373,3 +1462,86 @@ func WriteRefFile(refName, commitHash string) error {
 
 	return nil
 }
+
+// Remote describes a remote of the repository
+type Remote struct {
+	Name     string `json:"name"`
+	FetchURL string `json:"fetchUrl"`
+	PushURL  string `json:"pushUrl"`
+}
+
+// ListRemotes gets the remotes of the repository with their URLs, in the order of git remote -v
+func ListRemotes() ([]Remote, error) {
+	cmd := exec.Command("git", "remote", "-v")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	// Each remote has a "<name>\t<url> (fetch)" and a "<name>\t<url> (push)" line
+	var remotes []Remote
+	index := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name, rest, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+		url, kind, _ := strings.Cut(rest, " ")
+		i, known := index[name]
+		if !known {
+			i = len(remotes)
+			index[name] = i
+			remotes = append(remotes, Remote{Name: name})
+		}
+		switch kind {
+		case "(fetch)":
+			remotes[i].FetchURL = url
+		case "(push)":
+			remotes[i].PushURL = url
+		}
+	}
+	return remotes, nil
+}
+
+// Worktree describes a worktree of the repository
+type Worktree struct {
+	Path     string `json:"path"`
+	Head     string `json:"head"`
+	Branch   string `json:"branch"`
+	Bare     bool   `json:"bare"`
+	Detached bool   `json:"detached"`
+}
+
+// ListWorktrees gets the main worktree and the linked worktrees of the repository
+func ListWorktrees() ([]Worktree, error) {
+	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	// Worktrees are separated by blank lines, each line is an attribute
+	var worktrees []Worktree
+	for _, block := range strings.Split(strings.TrimSpace(string(output)), "\n\n") {
+		var worktree Worktree
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				worktree.Path = value
+			case "HEAD":
+				worktree.Head = value
+			case "branch":
+				worktree.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare":
+				worktree.Bare = true
+			case "detached":
+				worktree.Detached = true
+			}
+		}
+		if worktree.Path != "" {
+			worktrees = append(worktrees, worktree)
+		}
+	}
+	return worktrees, nil
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"git-tools/common/testutil"
+)
+
+func TestGitRefExistsRejectsMissingRelativeExpression(t *testing.T) {
+	testutil.NewRepo(t)
+	testutil.Commit(t, "first")
+
+	if GitRefExists("main~2") {
+		t.Errorf("GitRefExists accepted main~2 on a branch with a single commit")
+	}
+}
+
+func TestConflictedFilesOnlyListsUnmergedPaths(t *testing.T) {
+	testutil.NewRepo(t)
+	testutil.WriteFile(t, "conflict", "base\n")
+	testutil.Commit(t, "base")
+	testutil.Git(t, "checkout", "-q", "-b", "other")
+	testutil.WriteFile(t, "conflict", "other\n")
+	testutil.Commit(t, "other")
+	testutil.Git(t, "checkout", "-q", "main")
+	testutil.WriteFile(t, "conflict", "main\n")
+	testutil.Commit(t, "main")
+	if _, err := exec.Command("git", "merge", "other").CombinedOutput(); err == nil {
+		t.Fatal("the merge of other succeeded, want a conflict")
+	}
+
+	// A staged file deleted from the work tree shows as AD, it isn't a conflict
+	testutil.WriteFile(t, "added", "added\n")
+	if err := os.Remove("added"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ConflictedFiles()
+	if err != nil {
+		t.Fatalf("ConflictedFiles: %v", err)
+	}
+	if len(files) != 1 || files[0] != "conflict" {
+		t.Errorf("ConflictedFiles() = %v, want [conflict]", files)
+	}
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RefChange records where a reference pointed before and after an operation
+type RefChange struct {
+	Ref    string `json:"ref"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// operationEntry is one line of .git/git-tools/operations.log
+type operationEntry struct {
+	Time    string      `json:"time"`
+	Tool    string      `json:"tool"`
+	Args    []string    `json:"args"`
+	Result  string      `json:"result"`
+	Error   string      `json:"error,omitempty"`
+	Changes []RefChange `json:"changes,omitempty"`
+}
+
+// LogOperation appends a JSON line describing a run of a tool to .git/git-tools/operations.log.
+// Logging is best effort: the operation already happened, so a failure to write is ignored.
+func LogOperation(tool string, args []string, err error, changes ...RefChange) {
+	entry := operationEntry{
+		Time:    time.Now().Format(time.RFC3339),
+		Tool:    tool,
+		Args:    args,
+		Result:  "ok",
+		Changes: changes,
+	}
+	if entry.Args == nil {
+		entry.Args = []string{}
+	}
+	if err != nil {
+		entry.Result = "error"
+		entry.Error = err.Error()
+	}
+
+	line, jsonErr := json.Marshal(entry)
+	if jsonErr != nil {
+		return
+	}
+
+	toolsDir, dirErr := GetToolsDirectory()
+	if dirErr != nil {
+		return
+	}
+	f, openErr := os.OpenFile(filepath.Join(toolsDir, "operations.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if openErr != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
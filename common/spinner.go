@@ -0,0 +1,69 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// StatusSpinner shows an animated status line while a long operation runs.
+// When stdout is not a terminal, it prints the message once instead.
+type StatusSpinner struct {
+	message string
+	tty     bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewStatusSpinner creates a spinner for the given message, call Start to show it
+func NewStatusSpinner(message string) *StatusSpinner {
+	return &StatusSpinner{
+		message: message,
+		tty:     IsTerminal(os.Stdout),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start shows the status line and animates it until Stop is called
+func (s *StatusSpinner) Start() {
+	if !s.tty {
+		fmt.Printf("%s%s%s\n", ColorYellow, s.message, ColorReset)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			fmt.Printf("\r%s%s %s%s", ColorYellow, spinnerFrames[frame%len(spinnerFrames)], s.message, ColorReset)
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the animation and replaces the status line with a final result
+func (s *StatusSpinner) Stop(success bool) {
+	if !s.tty {
+		return
+	}
+
+	close(s.done)
+	s.wg.Wait()
+
+	// Clear the line before printing the final state
+	fmt.Print("\r\033[K")
+	if success {
+		fmt.Printf("%s✅ %s%s\n", ColorGreen, s.message, ColorReset)
+	} else {
+		fmt.Printf("%s❌ %s%s\n", ColorRed, s.message, ColorReset)
+	}
+}
@@ -0,0 +1,60 @@
+// Package testutil holds the git repository fixture shared by the tests of common and of the tools
+package testutil
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// NewRepo creates a repository with a main branch in a temporary directory and makes it the
+// working directory for the rest of the test. Global and system git config are ignored.
+func NewRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := t.TempDir()
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+
+	Git(t, "init", "-q", "-b", "main")
+	return dir
+}
+
+// Git runs a git command in the test repository and returns its trimmed output
+func Git(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Commit creates an empty commit and returns its hash
+func Commit(t *testing.T, message string) string {
+	t.Helper()
+	Git(t, "commit", "-q", "--allow-empty", "-m", message)
+	return Git(t, "rev-parse", "HEAD")
+}
+
+// WriteFile writes a file of the test repository and stages it
+func WriteFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	Git(t, "add", name)
+}
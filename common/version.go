@@ -0,0 +1,31 @@
+package common
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Version is the version of the tools, set at build time with
+// -ldflags "-X git-tools/common.Version=<version>"
+var Version = "dev"
+
+// GitVersion gets the version of the installed git, e.g. "2.43.0"
+func GitVersion() (string, error) {
+	cmd := exec.Command("git", "--version")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "git version "), nil
+}
+
+// PrintVersion prints the version of the tool and of git
+func PrintVersion(tool string) {
+	fmt.Printf("%s version %s\n", tool, Version)
+	if gitVersion, err := GitVersion(); err == nil {
+		fmt.Printf("git version %s\n", gitVersion)
+	} else {
+		fmt.Printf("git version unknown (%v)\n", err)
+	}
+}
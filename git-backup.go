@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -12,6 +14,11 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		common.PrintVersion("git-backup")
+		os.Exit(0)
+	}
+
 	if !common.IsGitRepository() {
 		fmt.Fprintf(os.Stderr, "%sError: This directory is not a git repository.%s\n", common.ColorRed, common.ColorReset)
 		os.Exit(1)
@@ -19,10 +26,19 @@ func main() {
 
 	var targetRef, targetBranch string
 	var err error
-	var purgeMode, forceMode, listMode bool
+	var purgeMode, forceMode, listMode, keepOnError, stashesMode, allMode, restoreMode, checkoutMode, noDirtyWarning bool
+	var excludes []string
+	var hook, restoreAs, nameTemplate string
+
+	cfg, err := common.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
 
 	var gitRef string
-	for i, arg := range os.Args[1:] {
+	for i := 1; i < len(os.Args); i++ {
+		arg := os.Args[i]
 		switch arg {
 		case "-h", "--help":
 			printUsage()
@@ -33,11 +49,59 @@ func main() {
 			forceMode = true
 		case "-l", "--list":
 			listMode = true
+		case "--keep-on-error":
+			keepOnError = true
+		case "--stashes":
+			stashesMode = true
+		case "--all":
+			allMode = true
+		case "--restore":
+			restoreMode = true
+		case "--restore-as":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "%sError: --restore-as requires a branch name%s\n", common.ColorRed, common.ColorReset)
+				os.Exit(1)
+			}
+			i++
+			restoreAs = os.Args[i]
+		case "--checkout":
+			checkoutMode = true
+		case "--no-dirty-warning":
+			noDirtyWarning = true
+		case "--name-template":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "%sError: --name-template requires a template%s\n", common.ColorRed, common.ColorReset)
+				os.Exit(1)
+			}
+			i++
+			nameTemplate = os.Args[i]
+			if err := validateNameTemplate(nameTemplate); err != nil {
+				fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+				os.Exit(1)
+			}
+		case "--hook":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "%sError: --hook requires a command%s\n", common.ColorRed, common.ColorReset)
+				os.Exit(1)
+			}
+			i++
+			hook = os.Args[i]
+		case "--exclude":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "%sError: --exclude requires a glob pattern%s\n", common.ColorRed, common.ColorReset)
+				os.Exit(1)
+			}
+			i++
+			if _, err := filepath.Match(os.Args[i], ""); err != nil {
+				fmt.Fprintf(os.Stderr, "%sError: Invalid --exclude pattern '%s'%s\n", common.ColorRed, os.Args[i], common.ColorReset)
+				os.Exit(1)
+			}
+			excludes = append(excludes, os.Args[i])
 		default:
-			if gitRef == "" && !purgeMode && !listMode {
+			if gitRef == "" && !purgeMode && !listMode && !stashesMode && !allMode {
 				gitRef = arg
-			} else if gitRef == "" && (purgeMode || listMode) {
-				fmt.Fprintf(os.Stderr, "%sError: --purge and --list do not accept a git reference argument%s\n", common.ColorRed, common.ColorReset)
+			} else if gitRef == "" && (purgeMode || listMode || stashesMode || allMode) {
+				fmt.Fprintf(os.Stderr, "%sError: --purge, --list, --stashes and --all do not accept a git reference argument%s\n", common.ColorRed, common.ColorReset)
 				os.Exit(1)
 			} else {
 				fmt.Fprintf(os.Stderr, "%sError: Unknown argument '%s'%s\n", common.ColorRed, arg, common.ColorReset)
@@ -45,16 +109,74 @@ func main() {
 				os.Exit(1)
 			}
 		}
-		_ = i // Suppress unused variable warning
+	}
+
+	if hook == "" {
+		hook = cfg.BackupPostHook
+	}
+
+	if nameTemplate == "" {
+		nameTemplate = defaultNameTemplate
+	} else if purgeMode || listMode || stashesMode || restoreMode || restoreAs != "" {
+		fmt.Fprintf(os.Stderr, "%sError: --name-template can only be used when creating backups%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
+	if len(excludes) > 0 && !allMode {
+		fmt.Fprintf(os.Stderr, "%sError: --exclude can only be used with --all%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
+	if checkoutMode && restoreAs == "" {
+		fmt.Fprintf(os.Stderr, "%sError: --checkout can only be used with --restore-as%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
+	if restoreMode && restoreAs != "" {
+		fmt.Fprintf(os.Stderr, "%sError: --restore and --restore-as cannot be combined%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
 	}
 
 	if purgeMode {
-		handlePurgeMode(forceMode)
+		err := handlePurgeMode(cfg.BackupPrefix, forceMode)
+		common.LogOperation("backup", os.Args[1:], err)
+		exitOnError(err)
 		return
 	}
 
 	if listMode {
-		handleListMode()
+		handleListMode(cfg.BackupPrefix)
+		return
+	}
+
+	if stashesMode {
+		err := handleStashesMode(cfg.BackupPrefix)
+		common.LogOperation("backup", os.Args[1:], err)
+		exitOnError(err)
+		return
+	}
+
+	if allMode {
+		err := handleAllMode(cfg.BackupPrefix, nameTemplate, excludes, keepOnError, hook)
+		common.LogOperation("backup", os.Args[1:], err)
+		exitOnError(err)
+		return
+	}
+
+	if restoreMode {
+		headBefore, _ := common.GetCommitHash("HEAD")
+		err := handleRestoreMode(cfg.BackupPrefix, gitRef, forceMode)
+		headAfter, _ := common.GetCommitHash("HEAD")
+		common.LogOperation("backup", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore, After: headAfter})
+		exitOnError(err)
+		return
+	}
+
+	if restoreAs != "" {
+		err := handleRestoreAsMode(cfg.BackupPrefix, gitRef, restoreAs, checkoutMode)
+		restoredCommit, _ := common.GetCommitHash(restoreAs)
+		common.LogOperation("backup", os.Args[1:], err, common.RefChange{Ref: restoreAs, After: restoredCommit})
+		exitOnError(err)
 		return
 	}
 
@@ -88,31 +210,26 @@ func main() {
 		fmt.Printf("%sCurrent branch: %s%s\n", common.ColorGreen, targetBranch, common.ColorReset)
 	}
 
-	if common.HasUncommittedChanges() {
-		fmt.Printf("%s⚠️  Warning: You have uncommitted changes in your working directory.%s\n", common.ColorYellow, common.ColorReset)
-		fmt.Printf("%s   The backup will capture the current state of the '%s' branch,\n", common.ColorYellow, targetBranch)
-		fmt.Printf("   but your uncommitted changes will not be included in the backup.%s\n", common.ColorReset)
-		fmt.Println()
+	if !noDirtyWarning && !common.IsBareRepository() {
+		warnAboutDirtyWorkTree(targetBranch)
 	}
 
 	// Get today's date in yyyy-mm-dd format
 	dateStr := time.Now().Format("2006-01-02")
 
-	baseBackupName := fmt.Sprintf("backups/%s/%s", targetBranch, dateStr)
-	existingBackups := getExistingBackups(baseBackupName)
-	backupNumber := getNextBackupNumber(existingBackups, baseBackupName)
-
-	var backupBranchName string
-	if backupNumber == 1 && !hasExactMatch(existingBackups, baseBackupName) {
-		backupBranchName = baseBackupName
-	} else {
-		backupBranchName = fmt.Sprintf("%s-%d", baseBackupName, backupNumber)
+	// Tools backing up before an operation label the backup with it
+	operation := os.Getenv(common.BackupOperationEnv)
+	backupBranchName, err := nextBackupName(nameTemplate, cfg.BackupPrefix, targetBranch, dateStr, operation)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
 	}
 
 	fmt.Printf("%s ▶️ Creating backup branch: %s%s\n", common.ColorYellow, backupBranchName, common.ColorReset)
 
-	if err := common.CreateBranch(backupBranchName, targetRef); err != nil {
-		fmt.Fprintf(os.Stderr, "%s❌ Failed to create backup branch: %s%s\n", common.ColorRed, err, common.ColorReset)
+	if err := createBackup(backupBranchName, targetRef, keepOnError, hook); err != nil {
+		common.LogOperation("backup", os.Args[1:], err)
+		fmt.Fprintf(os.Stderr, "%s❌ %s%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
 
@@ -122,6 +239,259 @@ func main() {
 	fmt.Printf("%sBackup Summary:%s\n", common.ColorCyan, common.ColorReset)
 	fmt.Printf("%s  Source reference: %s%s\n", common.ColorWhite, targetRef, common.ColorReset)
 	fmt.Printf("%s  Backup branch:    %s%s\n", common.ColorWhite, backupBranchName, common.ColorReset)
+
+	sourceType, isLoose := describeSource(targetRef, backupBranchName)
+	fmt.Printf("%s  Source type:      %s%s\n", common.ColorWhite, sourceType, common.ColorReset)
+	if isLoose {
+		fmt.Printf("%s⚠️  The backup branch is the only thing keeping this commit from being garbage collected.%s\n", common.ColorYellow, common.ColorReset)
+	}
+
+	backupCommit, _ := common.GetCommitHash(backupBranchName)
+	common.LogOperation("backup", os.Args[1:], nil, common.RefChange{Ref: backupBranchName, After: backupCommit})
+}
+
+// exitOnError prints the error of a mode and exits, once main logged it
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+}
+
+// runPostBackupHook runs the hook command through the shell. The backup branch is passed as
+// its first argument and in GIT_TOOLS_BACKUP_BRANCH.
+func runPostBackupHook(hook, backupBranchName string) error {
+	fmt.Printf("%s ▶️ Running post-backup hook...%s\n", common.ColorYellow, common.ColorReset)
+	cmd := exec.Command("sh", "-c", hook, "git-backup-hook", backupBranchName)
+	cmd.Env = append(os.Environ(), "GIT_TOOLS_BACKUP_BRANCH="+backupBranchName)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("post-backup hook failed with exit status %d", exitErr.ExitCode())
+		}
+		return fmt.Errorf("post-backup hook could not run: %v", err)
+	}
+	fmt.Printf("%s ✅ Post-backup hook succeeded%s\n", common.ColorGreen, common.ColorReset)
+	return nil
+}
+
+// describeSource classifies the backed up reference as a branch tip, a tag or a commit,
+// and reports whether the commit is not reachable from any branch other than the backup
+func describeSource(ref, backupBranchName string) (string, bool) {
+	switch common.ClassifyRef(ref) {
+	case common.RefBranch:
+		if common.IsBranch(ref) {
+			return "branch tip", false
+		}
+		return fmt.Sprintf("branch tip of '%s'", common.GetBranchName(ref)), false
+	case common.RefTag:
+		return "tag", false
+	case common.RefUnknown:
+		return "unknown", false
+	}
+
+	commitHash, err := common.GetCommitHash(ref)
+	if err != nil {
+		return "unknown", false
+	}
+
+	if branches, err := common.GetBranchesPointingAt(commitHash); err == nil {
+		branches = excludeBranch(branches, backupBranchName)
+		if len(branches) > 0 {
+			return fmt.Sprintf("commit at the tip of '%s'", strings.Join(branches, "', '")), false
+		}
+	}
+
+	branches, err := common.GetBranchesContaining(commitHash)
+	if err != nil {
+		return "commit", false
+	}
+	branches = excludeBranch(branches, backupBranchName)
+	if len(branches) == 0 {
+		return "loose commit (not reachable from any branch)", true
+	}
+	return fmt.Sprintf("commit reachable from '%s'", branches[0]), false
+}
+
+// defaultNameTemplate names backups <prefix>/<branch>/<date>[-number]
+const defaultNameTemplate = "{prefix}/{branch}/{date}"
+
+// backupTemplateRoot starts every name template, so backups stay under <prefix>/<branch>/
+const backupTemplateRoot = "{prefix}/{branch}/"
+
+var templatePlaceholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// validateNameTemplate checks that the template only uses known placeholders and expands to a valid branch name
+func validateNameTemplate(template string) error {
+	for _, placeholder := range templatePlaceholderPattern.FindAllString(template, -1) {
+		switch placeholder {
+		case "{prefix}", "{branch}", "{date}", "{user}", "{n}":
+		default:
+			return fmt.Errorf("unknown placeholder '%s' in name template, use {prefix}, {branch}, {date}, {user} or {n}", placeholder)
+		}
+	}
+	// --list, --purge, --restore and the --all skip of existing backups look under <prefix>/<branch>/
+	if !strings.HasPrefix(template, backupTemplateRoot) {
+		return fmt.Errorf("name template '%s' must start with %s, where the other modes look for backups", template, backupTemplateRoot)
+	}
+
+	sample := expandNameTemplate(template, "backups", "main", "2006-01-02", "user", "1")
+	if !common.IsValidRefName("refs/heads/" + sample) {
+		return fmt.Errorf("name template '%s' does not produce a valid branch name (e.g. '%s')", template, sample)
+	}
+	return nil
+}
+
+// expandNameTemplate replaces the placeholders of a name template
+func expandNameTemplate(template, backupPrefix, branch, dateStr, user, number string) string {
+	return strings.NewReplacer(
+		"{prefix}", backupPrefix,
+		"{branch}", branch,
+		"{date}", dateStr,
+		"{user}", user,
+		"{n}", number,
+	).Replace(template)
+}
+
+// backupBaseName builds the name of a backup before numbering from the template, the operation
+// being appended as a -<operation> suffix. It doesn't look at the repository, so it can be tested alone.
+func backupBaseName(template, backupPrefix, branch, dateStr, user, operation string) string {
+	name := expandNameTemplate(template, backupPrefix, branch, dateStr, user, "{n}")
+	if operation != "" {
+		name += "-" + operation
+	}
+	return name
+}
+
+// nextBackupName returns the first free backup name for the branch, date and operation. Templates
+// with {n} get the first free number from 1 there, others a -number suffix when the name is taken.
+func nextBackupName(template, backupPrefix, branch, dateStr, operation string) (string, error) {
+	user := ""
+	if strings.Contains(template, "{user}") {
+		user = common.GetUserHandle()
+	}
+	baseBackupName := backupBaseName(template, backupPrefix, branch, dateStr, user, operation)
+
+	var name string
+	if strings.Contains(baseBackupName, "{n}") {
+		for n := 1; ; n++ {
+			name = strings.ReplaceAll(baseBackupName, "{n}", strconv.Itoa(n))
+			if !common.IsBranch(name) {
+				break
+			}
+		}
+	} else {
+		existingBackups := getExistingBackups(baseBackupName)
+		backupNumber := common.NextNameNumber(existingBackups, baseBackupName)
+
+		name = baseBackupName
+		if backupNumber != 1 || hasExactMatch(existingBackups, baseBackupName) {
+			name = fmt.Sprintf("%s-%d", baseBackupName, backupNumber)
+		}
+	}
+
+	if !common.IsValidRefName("refs/heads/" + name) {
+		return "", fmt.Errorf("backup name '%s' is not a valid branch name", name)
+	}
+	return name, nil
+}
+
+// handleAllMode backs up every local branch, except existing backups and branches matching an exclude glob
+func handleAllMode(backupPrefix, nameTemplate string, excludes []string, keepOnError bool, hook string) error {
+	branches, err := common.GetLocalBranches()
+	if err != nil {
+		return fmt.Errorf("could not list branches: %v", err)
+	}
+
+	dateStr := time.Now().Format("2006-01-02")
+	skipped := 0
+	created := 0
+	failed := 0
+	for _, branch := range branches {
+		if strings.HasPrefix(branch, backupPrefix+"/") || strings.HasPrefix(branch, stashBackupPrefix(backupPrefix)) || isExcluded(branch, excludes) {
+			skipped++
+			continue
+		}
+
+		backupBranchName, err := nextBackupName(nameTemplate, backupPrefix, branch, dateStr, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s  ❌ %s: %s%s\n", common.ColorRed, branch, err, common.ColorReset)
+			failed++
+			continue
+		}
+		if err := createBackup(backupBranchName, branch, keepOnError, hook); err != nil {
+			fmt.Fprintf(os.Stderr, "%s  ❌ %s: %s%s\n", common.ColorRed, branch, err, common.ColorReset)
+			failed++
+			continue
+		}
+		fmt.Printf("%s  ✅ %s -> %s%s\n", common.ColorGreen, branch, backupBranchName, common.ColorReset)
+		created++
+	}
+
+	fmt.Println()
+	fmt.Printf("%sBackup Summary:%s\n", common.ColorCyan, common.ColorReset)
+	fmt.Printf("%s  Backed up:  %d branch(es)%s\n", common.ColorWhite, created, common.ColorReset)
+	fmt.Printf("%s  Skipped:    %d branch(es)%s\n", common.ColorWhite, skipped, common.ColorReset)
+	if failed > 0 {
+		fmt.Printf("%s  Failed:     %d branch(es)%s\n", common.ColorRed, failed, common.ColorReset)
+		return fmt.Errorf("%d branch(es) could not be backed up", failed)
+	}
+	return nil
+}
+
+// isExcluded checks if the branch matches one of the exclude globs
+func isExcluded(branch string, excludes []string) bool {
+	for _, pattern := range excludes {
+		if matched, _ := filepath.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// createBackup creates the backup branch, verifies it and runs the post-backup hook. If any
+// step after the branch creation fails, the branch is deleted unless keepOnError is set.
+func createBackup(backupBranchName, targetRef string, keepOnError bool, hook string) (err error) {
+	if err := common.CreateBranch(backupBranchName, targetRef); err != nil {
+		return fmt.Errorf("failed to create backup branch: %v", err)
+	}
+
+	defer func() {
+		if err == nil || keepOnError {
+			return
+		}
+		if delErr := common.DeleteBranch(backupBranchName); delErr != nil {
+			fmt.Fprintf(os.Stderr, "%sWarning: Could not remove partial backup '%s': %s%s\n", common.ColorYellow, backupBranchName, delErr, common.ColorReset)
+		} else {
+			fmt.Fprintf(os.Stderr, "%sRemoved partial backup '%s'%s\n", common.ColorYellow, backupBranchName, common.ColorReset)
+		}
+	}()
+
+	if err := verifyBackup(backupBranchName, targetRef); err != nil {
+		return err
+	}
+	if hook != "" {
+		return runPostBackupHook(hook, backupBranchName)
+	}
+	return nil
+}
+
+// verifyBackup checks that the backup branch points to the same commit as the source
+func verifyBackup(backupBranchName, targetRef string) error {
+	backupCommit, err := common.ResolveCommit(backupBranchName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve backup branch: %v", err)
+	}
+	// Tags resolve to their commit, which is what the backup branch points to
+	sourceCommit, err := common.ResolveCommit(targetRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve source reference: %v", err)
+	}
+	if backupCommit != sourceCommit {
+		return fmt.Errorf("backup branch points to %s but source is at %s", backupCommit[:8], sourceCommit[:8])
+	}
+	return nil
 }
 
 // getExistingBackups gets all existing backup branches for today
@@ -145,36 +515,6 @@ func getExistingBackups(baseBackupName string) []string {
 	return backups
 }
 
-func getNextBackupNumber(existingBackups []string, baseBackupName string) int {
-	if len(existingBackups) == 0 {
-		return 1
-	}
-
-	var numbers []int
-	numberPattern := fmt.Sprintf(`%s-(\d+)`, regexp.QuoteMeta(baseBackupName))
-	exactPattern := fmt.Sprintf(`^%s$`, regexp.QuoteMeta(baseBackupName))
-	
-	numberRegex := regexp.MustCompile(numberPattern)
-	exactRegex := regexp.MustCompile(exactPattern)
-
-	for _, backup := range existingBackups {
-		if matches := numberRegex.FindStringSubmatch(backup); matches != nil {
-			if num, err := strconv.Atoi(matches[1]); err == nil {
-				numbers = append(numbers, num)
-			}
-		} else if exactRegex.MatchString(backup) {
-			numbers = append(numbers, 0)
-		}
-	}
-
-	if len(numbers) == 0 {
-		return 1
-	}
-
-	sort.Ints(numbers)
-	return numbers[len(numbers)-1] + 1
-}
-
 func hasExactMatch(existingBackups []string, baseBackupName string) bool {
 	pattern := fmt.Sprintf(`^%s$`, regexp.QuoteMeta(baseBackupName))
 	regex := regexp.MustCompile(pattern)
@@ -187,19 +527,18 @@ func hasExactMatch(existingBackups []string, baseBackupName string) bool {
 	return false
 }
 
-func handlePurgeMode(forceMode bool) {
+func handlePurgeMode(backupPrefix string, forceMode bool) error {
 	currentBranch, err := common.GetCurrentBranch()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: Could not determine current branch name: %s%s\n", common.ColorRed, err, common.ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("could not determine current branch name: %v", err)
 	}
 
-	backupPattern := fmt.Sprintf("backups/%s/", currentBranch)
+	backupPattern := fmt.Sprintf("%s/%s/", backupPrefix, currentBranch)
 	backupBranches := getAllBackupBranches(backupPattern)
 
 	if len(backupBranches) == 0 {
 		fmt.Printf("%sNo backup branches found for branch '%s'%s\n", common.ColorYellow, currentBranch, common.ColorReset)
-		return
+		return nil
 	}
 
 	fmt.Printf("%sFound %d backup branch(es) for '%s':%s\n", common.ColorCyan, len(backupBranches), currentBranch, common.ColorReset)
@@ -217,7 +556,7 @@ func handlePurgeMode(forceMode bool) {
 		
 		if response != "y" && response != "Y" && response != "yes" && response != "YES" {
 			fmt.Printf("%sPurge operation cancelled%s\n", common.ColorYellow, common.ColorReset)
-			return
+			return nil
 		}
 	}
 
@@ -235,16 +574,20 @@ func handlePurgeMode(forceMode bool) {
 
 	fmt.Printf("%s🎉 Successfully deleted %d/%d backup branches for '%s'%s\n", 
 		common.ColorGreen, deletedCount, len(backupBranches), currentBranch, common.ColorReset)
+	if deletedCount < len(backupBranches) {
+		return fmt.Errorf("%d backup branch(es) could not be deleted", len(backupBranches)-deletedCount)
+	}
+	return nil
 }
 
-func handleListMode() {
+func handleListMode(backupPrefix string) {
 	currentBranch, err := common.GetCurrentBranch()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%sError: Could not determine current branch name: %s%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
 
-	backupPattern := fmt.Sprintf("backups/%s/", currentBranch)
+	backupPattern := fmt.Sprintf("%s/%s/", backupPrefix, currentBranch)
 	backupBranches := getAllBackupBranches(backupPattern)
 
 	if len(backupBranches) == 0 {
@@ -273,6 +616,229 @@ func handleListMode() {
 	fmt.Printf("\n%sTotal: %d backup(s)%s\n", common.ColorCyan, len(backupBranches), common.ColorReset)
 }
 
+// warnAboutDirtyWorkTree warns that uncommitted changes to tracked files are not part of the backup.
+// Untracked files are often build artifacts, so they only get a note.
+func warnAboutDirtyWorkTree(targetBranch string) {
+	total, err := common.CountUncommittedChanges()
+	if err != nil || total == 0 {
+		return
+	}
+	untracked, err := common.CountUntrackedFiles()
+	if err != nil {
+		return
+	}
+
+	if total == untracked {
+		fmt.Printf("Note: %d untracked file(s) will not be included in the backup.\n", untracked)
+		fmt.Println()
+		return
+	}
+
+	fmt.Printf("%s⚠️  Warning: You have uncommitted changes in your working directory.%s\n", common.ColorYellow, common.ColorReset)
+	fmt.Printf("%s   The backup will capture the current state of the '%s' branch,\n", common.ColorYellow, targetBranch)
+	fmt.Printf("   but your uncommitted changes will not be included in the backup.%s\n", common.ColorReset)
+	if untracked > 0 {
+		fmt.Printf("   %d untracked file(s) are not included either.\n", untracked)
+	}
+	fmt.Println()
+}
+
+// handleRestoreMode resets the current branch to one of its backups, picked from a menu if no name is given
+func handleRestoreMode(backupPrefix, backupName string, forceMode bool) error {
+	currentBranch, err := common.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("could not determine current branch name: %v", err)
+	}
+
+	if common.HasUncommittedChanges() {
+		return fmt.Errorf("there are uncommitted changes. Please commit or stash them before restoring a backup")
+	}
+
+	backupName, err = resolveBackupName(backupPrefix, backupName, currentBranch)
+	if err != nil {
+		return err
+	}
+
+	oldCommit, err := common.GetCommitHash("HEAD")
+	if err != nil {
+		return fmt.Errorf("could not get current commit: %v", err)
+	}
+
+	if !forceMode {
+		fmt.Printf("%sReset '%s' to '%s'? [y/N]: %s", common.ColorYellow, currentBranch, backupName, common.ColorReset)
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" && response != "yes" && response != "YES" {
+			fmt.Printf("%sRestore operation cancelled%s\n", common.ColorYellow, common.ColorReset)
+			return nil
+		}
+	}
+
+	if err := common.HardReset(backupName); err != nil {
+		return fmt.Errorf("failed to restore backup: %v", err)
+	}
+
+	fmt.Printf("%s✅ Branch '%s' restored from '%s'%s\n", common.ColorGreen, currentBranch, backupName, common.ColorReset)
+	fmt.Printf("%s   The previous tip was %s%s\n", common.ColorWhite, oldCommit[:8], common.ColorReset)
+	return nil
+}
+
+// handleRestoreAsMode creates a new branch at one of the current branch's backups, leaving the
+// current branch untouched
+func handleRestoreAsMode(backupPrefix, backupName, newBranch string, checkout bool) error {
+	currentBranch, err := common.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("could not determine current branch name: %v", err)
+	}
+
+	if common.IsBranch(newBranch) {
+		return fmt.Errorf("branch '%s' already exists", newBranch)
+	}
+
+	if checkout && common.HasUncommittedChanges() {
+		return fmt.Errorf("there are uncommitted changes. Please commit or stash them before checking out the restored branch")
+	}
+
+	backupName, err = resolveBackupName(backupPrefix, backupName, currentBranch)
+	if err != nil {
+		return err
+	}
+
+	if err := common.CreateBranch(newBranch, backupName); err != nil {
+		return fmt.Errorf("failed to create branch '%s': %v", newBranch, err)
+	}
+	fmt.Printf("%s✅ Branch '%s' created from '%s'%s\n", common.ColorGreen, newBranch, backupName, common.ColorReset)
+
+	if checkout {
+		if err := common.Checkout(newBranch); err != nil {
+			return fmt.Errorf("failed to check out '%s': %v", newBranch, err)
+		}
+		fmt.Printf("%s✅ Checked out '%s'%s\n", common.ColorGreen, newBranch, common.ColorReset)
+	}
+	return nil
+}
+
+// resolveBackupName returns the full name of the backup to restore, expanding the short form
+// or asking for one when none is given
+func resolveBackupName(backupPrefix, backupName, currentBranch string) (string, error) {
+	backupPattern := fmt.Sprintf("%s/%s/", backupPrefix, currentBranch)
+	if backupName == "" {
+		var err error
+		backupName, err = pickBackup(backupPattern, currentBranch)
+		if err != nil {
+			return "", err
+		}
+	} else if !common.IsBranch(backupName) && common.IsBranch(backupPattern+backupName) {
+		// Allow the short form, e.g. 2024-01-31-2 for backups/<branch>/2024-01-31-2
+		backupName = backupPattern + backupName
+	}
+
+	if !common.IsBranch(backupName) {
+		return "", fmt.Errorf("backup branch '%s' does not exist", backupName)
+	}
+	return backupName, nil
+}
+
+// pickBackup shows a numbered menu of the backups of the branch and returns the chosen one
+func pickBackup(backupPattern, currentBranch string) (string, error) {
+	backupBranches := getAllBackupBranches(backupPattern)
+	if len(backupBranches) == 0 {
+		return "", fmt.Errorf("no backup branches found for branch '%s'", currentBranch)
+	}
+	sort.Strings(backupBranches)
+
+	fmt.Printf("%sSelect a backup to restore '%s' from:%s\n", common.ColorCyan, currentBranch, common.ColorReset)
+	for i, branch := range backupBranches {
+		commitHash, err := common.GetCommitHash(branch)
+		if err != nil {
+			fmt.Printf("%s  %d. %s %s(commit unknown)%s\n", common.ColorWhite, i+1, branch, common.ColorYellow, common.ColorReset)
+			continue
+		}
+		commitMsg, _ := common.GetCommitMessage(branch)
+		fmt.Printf("%s  %d. %s %s(%s)%s - %s\n", common.ColorWhite, i+1, branch, common.ColorYellow, commitHash[:8], common.ColorReset, commitMsg)
+	}
+
+	fmt.Printf("\n%sEnter backup number (1-%d): %s", common.ColorYellow, len(backupBranches), common.ColorReset)
+	var choice int
+	if _, err := fmt.Scanln(&choice); err != nil {
+		return "", fmt.Errorf("invalid input")
+	}
+
+	if choice < 1 || choice > len(backupBranches) {
+		return "", fmt.Errorf("invalid choice: %d", choice)
+	}
+	return backupBranches[choice-1], nil
+}
+
+// handleStashesMode creates a ref for each stash entry so they survive git stash clear
+func handleStashesMode(backupPrefix string) error {
+	stashes, err := common.ListStashes()
+	if err != nil {
+		return fmt.Errorf("could not list stashes: %v", err)
+	}
+
+	if len(stashes) == 0 {
+		fmt.Printf("%sNo stash entries to back up%s\n", common.ColorYellow, common.ColorReset)
+		return nil
+	}
+
+	// Each run gets its own directory, a second run on the same day goes to <date>-2
+	runDir := nextStashRunDir(backupPrefix, time.Now().Format("2006-01-02"))
+	fmt.Printf("%s ▶️ Backing up %d stash entries...%s\n", common.ColorYellow, len(stashes), common.ColorReset)
+
+	failed := 0
+	for i, stash := range stashes {
+		refName := fmt.Sprintf("%s/%d", runDir, i)
+		if err := common.CreateBranchRef(refName, stash); err != nil {
+			fmt.Fprintf(os.Stderr, "%s❌ Failed to back up stash@{%d}: %s%s\n", common.ColorRed, i, err, common.ColorReset)
+			failed++
+			continue
+		}
+		fmt.Printf("%s  ✅ stash@{%d} -> %s%s\n", common.ColorGreen, i, refName, common.ColorReset)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d stash entries could not be backed up", failed)
+	}
+	fmt.Printf("%s ✅ Stash entries backed up under '%s/'%s\n", common.ColorGreen, runDir, common.ColorReset)
+	return nil
+}
+
+// stashBackupPrefix is where stash backups go. It sits next to the backup prefix rather than under
+// it, so backups of a branch named "stash" can't collide with them.
+func stashBackupPrefix(backupPrefix string) string {
+	return backupPrefix + "-stash/"
+}
+
+// nextStashRunDir returns the directory for this run's stash backups, numbered after the
+// directories earlier runs of the day used
+func nextStashRunDir(backupPrefix, dateStr string) string {
+	stashPrefix := stashBackupPrefix(backupPrefix)
+	var runDirs []string
+	for _, ref := range getAllBackupBranches(stashPrefix) {
+		if slash := strings.LastIndex(ref, "/"); slash >= len(stashPrefix) {
+			runDirs = append(runDirs, ref[len(stashPrefix):slash])
+		}
+	}
+
+	number := common.NextNameNumber(runDirs, dateStr)
+	if number == 1 && !hasExactMatch(runDirs, dateStr) {
+		return stashPrefix + dateStr
+	}
+	// <date>-1 would read as the second run, so numbering starts at 2
+	return fmt.Sprintf("%s%s-%d", stashPrefix, dateStr, max(number, 2))
+}
+
+func excludeBranch(branches []string, excluded string) []string {
+	var result []string
+	for _, branch := range branches {
+		if branch != excluded {
+			result = append(result, branch)
+		}
+	}
+	return result
+}
+
 func getAllBackupBranches(pattern string) []string {
 	branches, err := common.GetAllBranches()
 	if err != nil {
@@ -296,6 +862,10 @@ func printUsage() {
 	fmt.Println("Usage: git-backup [options] [reference]")
 	fmt.Println("       git-backup --purge [--force]")
 	fmt.Println("       git-backup --list")
+	fmt.Println("       git-backup --restore [backup] [--force]")
+	fmt.Println("       git-backup --restore-as <branch> [backup] [--checkout]")
+	fmt.Println("       git-backup --stashes")
+	fmt.Println("       git-backup --all [--exclude <glob>]...")
 	fmt.Println()
 	fmt.Println("Arguments:")
 	fmt.Println("  reference    Git reference to backup (branch, commit, tag)")
@@ -304,8 +874,21 @@ func printUsage() {
 	fmt.Println("Options:")
 	fmt.Println("  --list, -l   List all backup branches for the current branch")
 	fmt.Println("  --purge      Delete all backup branches for the current branch")
-	fmt.Println("  --force      Skip confirmation when using --purge")
+	fmt.Println("  --restore    Reset the current branch to one of its backups (pick from a menu if none is given)")
+	fmt.Println("  --restore-as <branch>  Create a new branch at one of the current branch's backups instead")
+	fmt.Println("  --checkout   Check out the branch created with --restore-as")
+	fmt.Println("  --force      Skip confirmation when using --purge or --restore")
+	fmt.Println("  --all        Back up every local branch (existing backups are skipped)")
+	fmt.Println("  --exclude <glob>  Skip branches matching the glob with --all (repeatable)")
+	fmt.Println("  --stashes    Back up every stash entry under backups-stash/<date>/<n>")
+	fmt.Println("               (later runs on the same day use <date>-2, <date>-3...)")
+	fmt.Println("  --hook <command>  Run a shell command after each backup, with the backup branch as $1")
+	fmt.Println("                    (default: git-tools.backup.post-hook config)")
+	fmt.Println("  --no-dirty-warning  Don't warn about uncommitted changes and untracked files")
+	fmt.Println("  --keep-on-error  Keep the backup branch if a step after its creation fails, including the hook")
+	fmt.Println("  --name-template <template>  Name backups after a template instead (see below)")
 	fmt.Println("  -h, --help   Show this help message")
+	fmt.Println("  --version    Show the version of the tool and git")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  git-backup                    # Backup current branch")
@@ -315,12 +898,26 @@ func printUsage() {
 	fmt.Println("  git-backup --list             # List all backup branches for current branch")
 	fmt.Println("  git-backup --purge            # Delete all backups of current branch (with confirmation)")
 	fmt.Println("  git-backup --purge --force    # Delete all backups of current branch (no confirmation)")
+	fmt.Println("  git-backup --restore          # Pick a backup to reset the current branch to")
+	fmt.Println("  git-backup --restore-as inspect --checkout  # Pick a backup to check out as branch 'inspect'")
+	fmt.Println("  git-backup --all --exclude 'tmp/*'  # Backup all branches except tmp/*")
+	fmt.Println("  git-backup --stashes          # Back up stash entries before a git stash clear")
+	fmt.Println("  git-backup --name-template '{prefix}/{branch}/{user}-{date}'  # Backup under a custom name")
 	fmt.Println()
 	fmt.Println("Backup branches are created under:")
 	fmt.Println("  backups/<branch-name>/<date>[-number]")
+	fmt.Println("  (the 'backups' prefix can be changed with 'git config git-tools.backup-prefix')")
 	fmt.Println()
 	fmt.Println("Where:")
 	fmt.Println("  <branch-name> is the source branch name")
 	fmt.Println("  <date> is today's date (yyyy-mm-dd)")
 	fmt.Println("  [-number] is added if multiple backups exist for the same day")
+	fmt.Println("  Backups taken by git reparent, git split and git move-branch are suffixed with the operation,")
+	fmt.Println("  e.g. backups/<branch-name>/<date>-reparent")
+	fmt.Println()
+	fmt.Println("Name templates:")
+	fmt.Println("  The default template is {prefix}/{branch}/{date}. Templates can use {prefix}, {branch}, {date},")
+	fmt.Println("  {user} (the local part of user.email) and {n} (the first free number, starting at 1).")
+	fmt.Println("  Without {n}, a -number suffix is added if the name is taken. Templates must start with")
+	fmt.Println("  {prefix}/{branch}/, where --list, --purge, --restore and --all look for backups.")
 }
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestBackupBaseName(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		operation string
+		want      string
+	}{
+		{"default template", defaultNameTemplate, "", "backups/feature/2024-05-01"},
+		{"operation suffix", defaultNameTemplate, "reparent", "backups/feature/2024-05-01-reparent"},
+		{"user placeholder", "{prefix}/{branch}/{user}-{date}", "split", "backups/feature/jdoe-2024-05-01-split"},
+		{"number left for numbering", "{prefix}/{branch}/{n}", "", "backups/feature/{n}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := backupBaseName(test.template, "backups", "feature", "2024-05-01", "jdoe", test.operation)
+			if got != test.want {
+				t.Errorf("backupBaseName() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateNameTemplate(t *testing.T) {
+	for _, template := range []string{defaultNameTemplate, "{prefix}/{branch}/{user}-{date}", "{prefix}/{branch}/{n}"} {
+		if err := validateNameTemplate(template); err != nil {
+			t.Errorf("validateNameTemplate(%q): %v", template, err)
+		}
+	}
+
+	for _, template := range []string{"{prefix}/{date}", "wip/{user}/{branch}-{date}", "{prefix}/{branch}-{date}", "{prefix}/{branch}/{time}", "{prefix}/{branch}/.."} {
+		if err := validateNameTemplate(template); err == nil {
+			t.Errorf("validateNameTemplate(%q) accepted an invalid template", template)
+		}
+	}
+}
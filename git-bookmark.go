@@ -1,29 +1,70 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"git-tools/common"
 )
 
+// bookmarkHistoryLimit is the number of checked out bookmarks kept in .git/BOOKMARK_HISTORY
+const bookmarkHistoryLimit = 50
+
+// bookmarksDir is .git/bookmarks, or the directory set through GIT_TOOLS_BOOKMARK_DIR or git-tools.bookmark.dir
+var bookmarksDir string
+
 type bookmarkOptions struct {
-	action      string
-	name        string
-	reference   string
-	absolute    bool
-	interactive bool
+	action          string
+	name            string
+	reference       string
+	absolute        bool
+	interactive     bool
+	quiet           bool
+	createIfMissing bool
+	count           int
+	yes             bool
+	json            bool
+	dryRun          bool
+	force           bool
+	keep            []string
+	pattern         string
+	stripPrefix     string
+	ttl             time.Duration
+	expired         bool
+	asBranch        string
+	autoName        bool
+	autostash       bool
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		common.PrintVersion("git-bookmark")
+		os.Exit(0)
+	}
+
 	if !common.IsGitRepository() {
 		fmt.Fprintf(os.Stderr, "%sError: This directory is not a git repository.%s\n", common.ColorRed, common.ColorReset)
 		os.Exit(1)
 	}
 
+	cfg, err := common.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+
+	if err := setBookmarksDir(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+
 	opts, err := parseArgs()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
@@ -31,14 +72,33 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Checking out needs a work tree, the other actions only read and write refs
+	switch opts.action {
+	case "checkout", "checkout-previous", "interactive":
+		if common.IsBareRepository() {
+			fmt.Fprintf(os.Stderr, "%sError: Cannot check out a bookmark in a bare repository%s\n", common.ColorRed, common.ColorReset)
+			os.Exit(1)
+		}
+	}
+
 	switch opts.action {
 	case "create":
-		if err := createBookmark(opts.name, opts.reference); err != nil {
+		if err := createBookmark(opts.name, opts.reference, opts.ttl, false); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	case "alias":
+		if err := createAlias(opts.name, opts.reference); err != nil {
 			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
 			os.Exit(1)
 		}
 	case "delete":
-		if err := deleteBookmark(opts.name); err != nil {
+		if strings.ContainsAny(opts.name, "*?[") {
+			err = deleteMatchingBookmarks(opts.name, opts.yes)
+		} else {
+			err = deleteBookmark(opts.name)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
 			os.Exit(1)
 		}
@@ -53,7 +113,10 @@ func main() {
 			os.Exit(1)
 		}
 	case "checkout":
-		if err := checkoutBookmark(opts.name); err != nil {
+		err := withAutostash(opts.autostash, opts.quiet, func() error {
+			return checkoutBookmark(opts.name, opts.quiet, opts.createIfMissing, opts.asBranch, opts.autoName)
+		})
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
 			os.Exit(1)
 		}
@@ -72,6 +135,42 @@ func main() {
 			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
 			os.Exit(1)
 		}
+	case "touch":
+		if err := touchBookmark(opts.name); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	case "import-tags":
+		if err := importTags(opts.pattern, opts.stripPrefix); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	case "recent":
+		if err := listRecentBookmarks(opts.count); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	case "stats":
+		if err := printBookmarkStats(opts.json); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	case "verify":
+		if err := verifyBookmarks(); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	case "gc":
+		// Deleting takes --force, a plain gc only reports the duplicates
+		if err := gcBookmarks(opts.keep, !opts.force); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	case "prune":
+		if err := pruneExpiredBookmarks(opts.dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
 	default:
 		fmt.Fprintf(os.Stderr, "%sError: Unknown action '%s'%s\n", common.ColorRed, opts.action, common.ColorReset)
 		printUsage()
@@ -80,7 +179,7 @@ func main() {
 }
 
 func parseArgs() (*bookmarkOptions, error) {
-	opts := &bookmarkOptions{}
+	opts := &bookmarkOptions{count: 10}
 	args := os.Args[1:]
 
 	if len(args) == 0 {
@@ -116,25 +215,83 @@ func parseArgs() (*bookmarkOptions, error) {
 			i++
 		case "--absolute", "-a":
 			opts.absolute = true
+		case "--quiet", "-q":
+			opts.quiet = true
+		case "--create-if-missing":
+			opts.createIfMissing = true
+		case "--yes", "-y":
+			opts.yes = true
+		case "--json":
+			opts.json = true
+		case "--dry-run":
+			opts.dryRun = true
+		case "--force", "-f":
+			opts.force = true
+		case "--keep":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			opts.keep = append(opts.keep, args[i+1])
+			i++
+		case "--pattern":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			opts.pattern = args[i+1]
+			i++
+		case "--ttl":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			ttl, err := parseTTL(args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			opts.ttl = ttl
+			i++
+		case "--expired":
+			opts.expired = true
+		case "--as-branch":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			opts.asBranch = args[i+1]
+			i++
+		case "--auto-name":
+			opts.autoName = true
+		case "--autostash":
+			opts.autostash = true
+		case "--strip-prefix":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			opts.stripPrefix = args[i+1]
+			i++
 		case "--help", "-h":
 			printUsage()
 			os.Exit(0)
 		default:
 			// Handle positional arguments based on action
-			if opts.action == "create" {
+			if opts.action == "create" || opts.action == "alias" {
 				if opts.name == "" {
 					opts.name = arg
 				} else if opts.reference == "" {
 					opts.reference = arg
 				} else {
-					return nil, fmt.Errorf("too many arguments for create action")
+					return nil, fmt.Errorf("too many arguments for %s action", opts.action)
 				}
-			} else if opts.action == "delete" || opts.action == "show" || opts.action == "checkout" || opts.action == "sync" {
+			} else if opts.action == "delete" || opts.action == "show" || opts.action == "checkout" || opts.action == "sync" || opts.action == "touch" {
 				if opts.name == "" {
 					opts.name = arg
 				} else {
 					return nil, fmt.Errorf("too many arguments for %s action", opts.action)
 				}
+			} else if opts.action == "recent" {
+				count, err := strconv.Atoi(arg)
+				if err != nil || count < 1 {
+					return nil, fmt.Errorf("recent expects a positive number of bookmarks, got '%s'", arg)
+				}
+				opts.count = count
 			} else {
 				return nil, fmt.Errorf("unknown argument: %s", arg)
 			}
@@ -142,27 +299,144 @@ func parseArgs() (*bookmarkOptions, error) {
 	}
 
 	switch opts.action {
-	case "create", "delete", "show", "checkout", "sync":
+	case "create", "delete", "show", "checkout", "sync", "touch":
 		if opts.name == "" {
 			return nil, fmt.Errorf("%s action requires a bookmark name", opts.action)
 		}
-	case "list":
+	case "alias":
+		if opts.name == "" || opts.reference == "" {
+			return nil, fmt.Errorf("alias action requires an alias name and a bookmark name")
+		}
+	case "list", "gc", "import-tags", "verify", "stats", "recent":
+	case "prune":
+		// Expiry is the only thing to prune on for now, but keep room for other criteria
+		if !opts.expired {
+			return nil, fmt.Errorf("prune requires --expired")
+		}
 	default:
 		return nil, fmt.Errorf("unknown action: %s", opts.action)
 	}
 
+	if opts.ttl != 0 && opts.action != "create" {
+		return nil, fmt.Errorf("--ttl can only be used with create")
+	}
+	if opts.asBranch != "" && opts.action != "checkout" {
+		return nil, fmt.Errorf("--as-branch can only be used with checkout")
+	}
+	if opts.autostash && opts.action != "checkout" {
+		return nil, fmt.Errorf("--autostash can only be used with checkout")
+	}
+	if opts.autoName && opts.asBranch == "" {
+		return nil, fmt.Errorf("--auto-name can only be used with --as-branch")
+	}
+	if opts.force && opts.action != "gc" {
+		return nil, fmt.Errorf("--force can only be used with gc")
+	}
+	if opts.force && opts.dryRun {
+		return nil, fmt.Errorf("--force and --dry-run cannot be combined")
+	}
+
 	return opts, nil
 }
 
-func getBookmarksDir() (string, error) {
-	gitDir, err := common.GetGitDirectory()
+// parseTTL parses a Go duration like 90m or 12h, also accepting a number of days like 7d
+func parseTTL(value string) (time.Duration, error) {
+	var ttl time.Duration
+	if days, found := strings.CutSuffix(value, "d"); found {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --ttl '%s', use a duration like 12h or 7d", value)
+		}
+		ttl = time.Duration(count) * 24 * time.Hour
+	} else {
+		var err error
+		if ttl, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("invalid --ttl '%s', use a duration like 12h or 7d", value)
+		}
+	}
+
+	if ttl <= 0 {
+		return 0, fmt.Errorf("--ttl must be positive, got '%s'", value)
+	}
+	return ttl, nil
+}
+
+// bookmarkExpiry returns the expiry of the bookmark an expression like 'fixes~2' is based on
+func bookmarkExpiry(expression string) (time.Time, bool) {
+	name := expression
+	if _, err := os.Stat(filepath.Join(bookmarksDir, name)); os.IsNotExist(err) {
+		if index := strings.IndexAny(name, "~^"); index > 0 {
+			name = name[:index]
+		}
+	}
+	expires, hasExpiry, err := common.GetBookmarkExpiry(bookmarksDir, name)
 	if err != nil {
-		return "", err
+		return time.Time{}, false
+	}
+	return expires, hasExpiry
+}
+
+// setBookmarksDir picks the bookmark directory from GIT_TOOLS_BOOKMARK_DIR, then git-tools.bookmark.dir,
+// then .git/bookmarks. The directory is created if needed.
+func setBookmarksDir(cfg *common.Config) error {
+	dir, err := common.GetBookmarksDirectory(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("bookmark directory '%s' is not usable: %v", dir, err)
+	}
+	bookmarksDir = dir
+	return nil
+}
+
+// getBookmarkNames returns the sorted names of all bookmarks, including nested ones like 'review/x'
+func getBookmarkNames() ([]string, error) {
+	if _, err := os.Stat(bookmarksDir); os.IsNotExist(err) {
+		return []string{}, nil
+	}
+
+	var bookmarks []string
+	err := filepath.WalkDir(bookmarksDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		name, err := filepath.Rel(bookmarksDir, path)
+		if err != nil {
+			return err
+		}
+		bookmarks = append(bookmarks, filepath.ToSlash(name))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks directory: %v", err)
+	}
+
+	sort.Strings(bookmarks)
+	return bookmarks, nil
+}
+
+// writeBookmark stores the reference in the bookmark file, creating directories as needed.
+// A non-zero expires is stored on a second line.
+func writeBookmark(name, reference string, expires time.Time) error {
+	bookmarkFile := filepath.Join(bookmarksDir, name)
+	if err := os.MkdirAll(filepath.Dir(bookmarkFile), 0755); err != nil {
+		return fmt.Errorf("failed to create bookmarks directory: %v", err)
+	}
+
+	content := reference + "\n"
+	if !expires.IsZero() {
+		content += common.BookmarkExpiresPrefix + expires.UTC().Format(time.RFC3339) + "\n"
 	}
-	return filepath.Join(gitDir, "bookmarks"), nil
+	return os.WriteFile(bookmarkFile, []byte(content), 0644)
 }
 
-func createBookmark(name, reference string) error {
+// createBookmark creates a bookmark, expiring after ttl unless it is zero. quiet leaves only errors.
+func createBookmark(name, reference string, ttl time.Duration, quiet bool) error {
 	if reference == "" {
 		// Use current branch/HEAD if no reference specified
 		currentBranch, err := common.GetCurrentBranch()
@@ -178,35 +452,50 @@ func createBookmark(name, reference string) error {
 		return fmt.Errorf("reference '%s' does not exist", reference)
 	}
 
-	bookmarksDir, err := getBookmarksDir()
-	if err != nil {
-		return err
+	var expires time.Time
+	if ttl != 0 {
+		expires = time.Now().Add(ttl)
 	}
-
-	if err := os.MkdirAll(bookmarksDir, 0755); err != nil {
-		return fmt.Errorf("failed to create bookmarks directory: %v", err)
-	}
-
-	bookmarkFile := filepath.Join(bookmarksDir, name)
-
-	if err := os.WriteFile(bookmarkFile, []byte(reference+"\n"), 0644); err != nil {
+	if err := writeBookmark(name, reference, expires); err != nil {
 		return fmt.Errorf("failed to create bookmark: %v", err)
 	}
 
-	if err := updatePreviousBookmark(name); err != nil {
+	if err := updatePreviousBookmark(name); err != nil && !quiet {
 		fmt.Printf("%sWarning: Failed to update previous bookmark tracking: %v%s\n", common.ColorYellow, err, common.ColorReset)
 	}
 
+	if quiet {
+		return nil
+	}
 	fmt.Printf("%s✅ Bookmark '%s' created pointing to '%s'%s\n", common.ColorGreen, name, reference, common.ColorReset)
+	if !expires.IsZero() {
+		fmt.Printf("%s   It expires on %s, delete expired bookmarks with 'git bookmark prune --expired'%s\n", common.ColorWhite, expires.Format("2006-01-02 15:04"), common.ColorReset)
+	}
 	return nil
 }
 
-func deleteBookmark(name string) error {
-	bookmarksDir, err := getBookmarksDir()
+// touchBookmark moves an existing bookmark to the current branch, or to the HEAD commit if detached
+func touchBookmark(name string) error {
+	if _, err := common.GetBookmarkReference(bookmarksDir, name); err != nil {
+		return err
+	}
+
+	reference, err := currentReference()
 	if err != nil {
 		return err
 	}
 
+	// Moving a bookmark keeps its expiry
+	expires, _ := bookmarkExpiry(name)
+	if err := writeBookmark(name, reference, expires); err != nil {
+		return fmt.Errorf("failed to update bookmark: %v", err)
+	}
+
+	fmt.Printf("%s✅ Bookmark '%s' now points to '%s'%s\n", common.ColorGreen, name, reference, common.ColorReset)
+	return nil
+}
+
+func deleteBookmark(name string) error {
 	bookmarkFile := filepath.Join(bookmarksDir, name)
 
 	if _, err := os.Stat(bookmarkFile); os.IsNotExist(err) {
@@ -221,8 +510,53 @@ func deleteBookmark(name string) error {
 	return nil
 }
 
+// deleteMatchingBookmarks deletes all bookmarks whose name matches the glob, after confirmation.
+// Deleting every bookmark with a bare '*' always asks, even with --yes.
+func deleteMatchingBookmarks(pattern string, yes bool) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern '%s'", pattern)
+	}
+
+	names, err := getBookmarkNames()
+	if err != nil {
+		return err
+	}
+
+	var matching []string
+	for _, name := range names {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			matching = append(matching, name)
+		}
+	}
+
+	if len(matching) == 0 {
+		return fmt.Errorf("no bookmark matches '%s'", pattern)
+	}
+
+	if !yes || pattern == "*" {
+		fmt.Printf("%sBookmarks matching '%s':%s\n", common.ColorCyan, pattern, common.ColorReset)
+		for _, name := range matching {
+			fmt.Printf("%s  %s%s\n", common.ColorWhite, name, common.ColorReset)
+		}
+		fmt.Printf("%sDelete %d bookmark(s)? (y/N): %s", common.ColorYellow, len(matching), common.ColorReset)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Printf("%sDeletion cancelled%s\n", common.ColorYellow, common.ColorReset)
+			return nil
+		}
+	}
+
+	for _, name := range matching {
+		if err := deleteBookmark(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func showBookmark(name string, absolute bool) error {
-	reference, err := getBookmarkReference(name)
+	reference, err := common.GetBookmarkReference(bookmarksDir, name)
 	if err != nil {
 		return err
 	}
@@ -241,116 +575,228 @@ func showBookmark(name string, absolute bool) error {
 }
 
 func listBookmarks() error {
-	bookmarksDir, err := getBookmarksDir()
+	bookmarks, err := getBookmarkNames()
 	if err != nil {
 		return err
 	}
 
-	if _, err := os.Stat(bookmarksDir); os.IsNotExist(err) {
-		fmt.Printf("%sNo bookmarks found%s\n", common.ColorYellow, common.ColorReset)
-		return nil
-	}
-
-	entries, err := os.ReadDir(bookmarksDir)
-	if err != nil {
-		return fmt.Errorf("failed to read bookmarks directory: %v", err)
-	}
-
-	if len(entries) == 0 {
+	if len(bookmarks) == 0 {
 		fmt.Printf("%sNo bookmarks found%s\n", common.ColorYellow, common.ColorReset)
 		return nil
 	}
 
 	fmt.Printf("%sBookmarks:%s\n", common.ColorCyan, common.ColorReset)
 
-	var bookmarks []string
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			bookmarks = append(bookmarks, entry.Name())
-		}
-	}
-	sort.Strings(bookmarks)
-
 	for _, name := range bookmarks {
-		reference, err := getBookmarkReference(name)
+		reference, err := common.GetBookmarkReference(bookmarksDir, name)
 		if err != nil {
 			fmt.Printf("%s  %s - %s(error: %v)%s\n", common.ColorWhite, name, common.ColorRed, err, common.ColorReset)
 			continue
 		}
 
+		// Show the bookmark an alias goes through
+		target := name
+		if content, err := common.ReadBookmarkFile(bookmarksDir, name); err == nil && strings.HasPrefix(content, common.BookmarkAliasPrefix) {
+			target = fmt.Sprintf("%s -> @%s", name, strings.TrimPrefix(content, common.BookmarkAliasPrefix))
+		}
+
+		expiry := ""
+		if expires, hasExpiry := bookmarkExpiry(name); hasExpiry && expires.Before(time.Now()) {
+			expiry = fmt.Sprintf(" %s(expired)", common.ColorRed)
+		}
+
 		commitHash, err := common.GetCommitHash(reference)
 		if err != nil {
-			fmt.Printf("%s  %s -> %s%s\n", common.ColorWhite, name, reference, common.ColorReset)
+			fmt.Printf("%s  %s -> %s%s%s\n", common.ColorWhite, target, reference, expiry, common.ColorReset)
 		} else {
-			fmt.Printf("%s  %s -> %s %s(%s)%s\n", common.ColorWhite, name, reference, common.ColorYellow, commitHash[:8], common.ColorReset)
+			fmt.Printf("%s  %s -> %s %s(%s)%s%s\n", common.ColorWhite, target, reference, common.ColorYellow, commitHash[:8], expiry, common.ColorReset)
 		}
 	}
 
 	return nil
 }
 
-func checkoutBookmark(name string) error {
-	reference, err := getBookmarkReference(name)
+// createAlias creates a bookmark that always resolves to what the target bookmark points to
+func createAlias(name, target string) error {
+	if name == target {
+		return fmt.Errorf("a bookmark cannot be an alias of itself")
+	}
+
+	content, err := common.ReadBookmarkFile(bookmarksDir, target)
 	if err != nil {
 		return err
 	}
+	if strings.HasPrefix(content, common.BookmarkAliasPrefix) {
+		return fmt.Errorf("bookmark '%s' is itself an alias, point to '%s' instead", target, strings.TrimPrefix(content, common.BookmarkAliasPrefix))
+	}
 
-	if err := updatePreviousBookmark(name); err != nil {
-		fmt.Printf("%sWarning: Failed to update previous bookmark tracking: %v%s\n", common.ColorYellow, err, common.ColorReset)
+	if err := writeBookmark(name, common.BookmarkAliasPrefix+target, time.Time{}); err != nil {
+		return fmt.Errorf("failed to create alias: %v", err)
+	}
+
+	fmt.Printf("%s✅ Bookmark '%s' created as an alias of '%s'%s\n", common.ColorGreen, name, target, common.ColorReset)
+	return nil
+}
+
+// currentReference returns the current branch, or the HEAD commit if detached
+func currentReference() (string, error) {
+	reference, err := common.GetCurrentBranch()
+	if err != nil {
+		reference, err = common.GetCommitHash("HEAD")
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve HEAD: %v", err)
+		}
+	}
+	return reference, nil
+}
+
+func checkoutBookmark(name string, quiet, createIfMissing bool, asBranch string, autoName bool) error {
+	reference, err := common.ResolveBookmarkExpression(bookmarksDir, name)
+	if err != nil {
+		// Offer to create a missing bookmark where we are, scripts keep getting the error
+		_, statErr := os.Stat(filepath.Join(bookmarksDir, name))
+		if os.IsNotExist(statErr) && !strings.ContainsAny(name, "~^") && (createIfMissing || confirmCreateMissing(name, quiet)) {
+			current, err := currentReference()
+			if err != nil {
+				return err
+			}
+			return createBookmark(name, current, 0, quiet)
+		}
+		return err
 	}
 
-	if err := common.Checkout(reference); err != nil {
+	// Expired bookmarks still work until pruned, they are only flagged
+	if expires, hasExpiry := bookmarkExpiry(name); hasExpiry && expires.Before(time.Now()) && !quiet {
+		fmt.Printf("%sWarning: Bookmark '%s' expired on %s%s\n", common.ColorYellow, name, expires.Local().Format("2006-01-02 15:04"), common.ColorReset)
+	}
+
+	if asBranch != "" {
+		branch, err := branchNameForCheckout(asBranch, autoName)
+		if err != nil {
+			return err
+		}
+		if err := common.CheckoutNewBranch(branch, reference); err != nil {
+			return fmt.Errorf("failed to create and checkout branch '%s': %v", branch, err)
+		}
+		if !quiet {
+			fmt.Printf("%s▶️ Created branch '%s' at bookmark '%s'%s\n", common.ColorYellow, branch, name, common.ColorReset)
+		}
+	} else if err := common.Checkout(reference); err != nil {
 		return fmt.Errorf("failed to checkout bookmark: %v", err)
 	}
 
-	fmt.Printf("%s✅ Checked out bookmark '%s' (%s -> %s)%s\n", common.ColorGreen, name, reference, reference[:8], common.ColorReset)
+	// Only track the bookmark once it was actually checked out
+	if err := updatePreviousBookmark(name); err != nil && !quiet {
+		fmt.Printf("%sWarning: Failed to update previous bookmark tracking: %v%s\n", common.ColorYellow, err, common.ColorReset)
+	}
+	if err := recordBookmarkHistory(name); err != nil && !quiet {
+		fmt.Printf("%sWarning: Failed to update bookmark history: %v%s\n", common.ColorYellow, err, common.ColorReset)
+	}
+
+	if quiet {
+		return nil
+	}
+
+	commitHash, err := common.GetCommitHash("HEAD")
+	if err != nil {
+		commitHash = "unknown"
+	}
+	fmt.Printf("%s✅ Checked out bookmark '%s' (%s -> %s)%s\n", common.ColorGreen, name, reference, commitHash[:min(8, len(commitHash))], common.ColorReset)
 	return nil
 }
 
-func checkoutPreviousBookmark() error {
-	previousName, err := getPreviousBookmark()
+// withAutostash runs checkout with the local changes stashed, and brings them back afterwards.
+// If they don't apply cleanly, the stash entry is kept for the user to resolve.
+func withAutostash(enabled, quiet bool, checkout func() error) error {
+	if !enabled {
+		return checkout()
+	}
+
+	stashed, err := common.StashPush("git-bookmark autostash")
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to stash local changes: %v", err)
+	}
+	if stashed && !quiet {
+		fmt.Printf("%s▶️ Stashed local changes%s\n", common.ColorYellow, common.ColorReset)
 	}
 
-	if previousName == "" {
-		return fmt.Errorf("no previous bookmark to checkout")
+	checkoutErr := checkout()
+	if !stashed {
+		return checkoutErr
 	}
 
-	return checkoutBookmark(previousName)
+	if err := common.StashPop(); err != nil {
+		if conflicts, _ := common.ConflictedFiles(); len(conflicts) > 0 {
+			return fmt.Errorf("local changes conflict in %s and are kept in stash@{0}. Resolve the conflicts, then run 'git stash drop'", strings.Join(conflicts, ", "))
+		}
+		return fmt.Errorf("failed to restore local changes, they are kept in stash@{0}: %v", err)
+	}
+	if !quiet {
+		fmt.Printf("%s✅ Restored local changes%s\n", common.ColorGreen, common.ColorReset)
+	}
+	return checkoutErr
 }
 
-func interactiveCheckout() error {
-	bookmarksDir, err := getBookmarksDir()
+// branchNameForCheckout returns the branch to create for --as-branch. Branches are shared by all
+// worktrees, so with autoName a taken name gets the next free suffix: name, name-2, name-3...
+func branchNameForCheckout(name string, autoName bool) (string, error) {
+	if !common.IsValidRefName("refs/heads/" + name) {
+		return "", fmt.Errorf("'%s' is not a valid branch name", name)
+	}
+
+	branches, err := common.GetLocalBranches()
 	if err != nil {
-		return err
+		return "", err
+	}
+	if !slices.Contains(branches, name) {
+		return name, nil
+	}
+	if !autoName {
+		return "", fmt.Errorf("branch '%s' already exists, use --auto-name to pick the next free name", name)
 	}
 
-	if _, err := os.Stat(bookmarksDir); os.IsNotExist(err) {
-		return fmt.Errorf("no bookmarks found")
+	// name-1 would read as the second of its kind, so numbering starts at 2
+	return fmt.Sprintf("%s-%d", name, max(common.NextNameNumber(branches, name), 2)), nil
+}
+
+// confirmCreateMissing asks whether to create a missing bookmark, when there is someone to ask
+func confirmCreateMissing(name string, quiet bool) bool {
+	if quiet || !common.IsTerminal(os.Stdin) {
+		return false
 	}
 
-	entries, err := os.ReadDir(bookmarksDir)
+	fmt.Printf("%sBookmark '%s' does not exist. Create it at the current branch/HEAD? (y/N): %s", common.ColorYellow, name, common.ColorReset)
+	var response string
+	fmt.Scanln(&response)
+	return strings.ToLower(response) == "y" || strings.ToLower(response) == "yes"
+}
+
+func checkoutPreviousBookmark() error {
+	previousName, err := getPreviousBookmark()
 	if err != nil {
-		return fmt.Errorf("failed to read bookmarks directory: %v", err)
+		return err
 	}
 
-	var bookmarks []string
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			bookmarks = append(bookmarks, entry.Name())
-		}
+	if previousName == "" {
+		return fmt.Errorf("no previous bookmark to checkout")
+	}
+
+	return checkoutBookmark(previousName, false, false, "", false)
+}
+
+func interactiveCheckout() error {
+	bookmarks, err := getBookmarkNames()
+	if err != nil {
+		return err
 	}
 
 	if len(bookmarks) == 0 {
 		return fmt.Errorf("no bookmarks found")
 	}
 
-	sort.Strings(bookmarks)
-
 	fmt.Printf("%sSelect a bookmark to checkout:%s\n", common.ColorCyan, common.ColorReset)
 	for i, name := range bookmarks {
-		reference, err := getBookmarkReference(name)
+		reference, err := common.GetBookmarkReference(bookmarksDir, name)
 		if err != nil {
 			fmt.Printf("%s  %d. %s %s(error)%s\n", common.ColorWhite, i+1, name, common.ColorRed, common.ColorReset)
 			continue
@@ -375,11 +821,11 @@ func interactiveCheckout() error {
 	}
 
 	selectedBookmark := bookmarks[choice-1]
-	return checkoutBookmark(selectedBookmark)
+	return checkoutBookmark(selectedBookmark, false, false, "", false)
 }
 
 func syncBranchFromBookmark(name string) error {
-	reference, err := getBookmarkReference(name)
+	reference, err := common.GetBookmarkReference(bookmarksDir, name)
 	if err != nil {
 		return err
 	}
@@ -405,24 +851,286 @@ func syncBranchFromBookmark(name string) error {
 	return nil
 }
 
-func getBookmarkReference(name string) (string, error) {
-	bookmarksDir, err := getBookmarksDir()
+// verifyBookmarks resolves every bookmark and reports the ones that are broken or point
+// to a commit no branch contains, which typically happens after a history rewrite
+func verifyBookmarks() error {
+	bookmarks, err := getBookmarkNames()
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	bookmarkFile := filepath.Join(bookmarksDir, name)
+	if len(bookmarks) == 0 {
+		fmt.Printf("%sNo bookmarks found%s\n", common.ColorYellow, common.ColorReset)
+		return nil
+	}
 
-	if _, err := os.Stat(bookmarkFile); os.IsNotExist(err) {
-		return "", fmt.Errorf("bookmark '%s' does not exist", name)
+	broken := 0
+	detached := 0
+	for _, name := range bookmarks {
+		reference, err := common.GetBookmarkReference(bookmarksDir, name)
+		if err != nil {
+			fmt.Printf("%s  ✗ %s - %v%s\n", common.ColorRed, name, err, common.ColorReset)
+			broken++
+			continue
+		}
+
+		commitHash, err := common.GetCommitHash(reference)
+		if err != nil {
+			fmt.Printf("%s  ✗ %s -> %s (does not resolve)%s\n", common.ColorRed, name, reference, common.ColorReset)
+			broken++
+			continue
+		}
+
+		subject, _ := common.GetCommitMessage(commitHash)
+		branches, err := common.GetBranchesContaining(commitHash)
+		if err == nil && len(branches) == 0 {
+			fmt.Printf("%s  ! %s -> %s (%s) %s - not on any branch%s\n", common.ColorYellow, name, reference, commitHash[:8], subject, common.ColorReset)
+			detached++
+			continue
+		}
+
+		fmt.Printf("%s  ✓ %s -> %s %s(%s)%s %s\n", common.ColorWhite, name, reference, common.ColorYellow, commitHash[:8], common.ColorReset, subject)
+	}
+
+	fmt.Println()
+	fmt.Printf("%s%d bookmark(s): %d ok, %d not on any branch, %d broken%s\n", common.ColorCyan, len(bookmarks), len(bookmarks)-broken-detached, detached, broken, common.ColorReset)
+	if broken > 0 {
+		return fmt.Errorf("%d bookmark(s) do not resolve", broken)
 	}
+	return nil
+}
 
-	content, err := os.ReadFile(bookmarkFile)
+// bookmarkStats is a summary of the health of the bookmarks
+type bookmarkStats struct {
+	Total         int `json:"total"`
+	Resolving     int `json:"resolving"`
+	Broken        int `json:"broken"`
+	ReachableHead int `json:"reachableFromHead"`
+}
+
+// printBookmarkStats counts the bookmarks that resolve, are broken, or point to commits reachable from HEAD
+func printBookmarkStats(asJSON bool) error {
+	bookmarks, err := getBookmarkNames()
 	if err != nil {
-		return "", fmt.Errorf("failed to read bookmark: %v", err)
+		return err
 	}
 
-	return strings.TrimSpace(string(content)), nil
+	stats := bookmarkStats{Total: len(bookmarks)}
+	for _, name := range bookmarks {
+		reference, err := common.GetBookmarkReference(bookmarksDir, name)
+		if err != nil {
+			stats.Broken++
+			continue
+		}
+
+		commitHash, err := common.GetCommitHash(reference)
+		if err != nil {
+			stats.Broken++
+			continue
+		}
+		stats.Resolving++
+
+		if reachable, err := common.IsAncestor(commitHash, "HEAD"); err == nil && reachable {
+			stats.ReachableHead++
+		}
+	}
+
+	if asJSON {
+		output, _ := json.MarshalIndent(stats, "", "  ")
+		fmt.Println(string(output))
+		return nil
+	}
+
+	fmt.Printf("%sBookmark Stats:%s\n", common.ColorCyan, common.ColorReset)
+	fmt.Printf("%s  Total:                %d%s\n", common.ColorWhite, stats.Total, common.ColorReset)
+	fmt.Printf("%s  Resolving:            %d%s\n", common.ColorWhite, stats.Resolving, common.ColorReset)
+	fmt.Printf("%s  Broken:               %d%s\n", common.ColorWhite, stats.Broken, common.ColorReset)
+	fmt.Printf("%s  Reachable from HEAD:  %d%s\n", common.ColorWhite, stats.ReachableHead, common.ColorReset)
+	return nil
+}
+
+// importTags creates a bookmark for each tag matching the pattern, named after the tag
+// minus stripPrefix. Existing bookmarks are left untouched.
+func importTags(pattern, stripPrefix string) error {
+	tags, err := common.ListTags(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %v", err)
+	}
+
+	if len(tags) == 0 {
+		fmt.Printf("%sNo tags matching '%s'%s\n", common.ColorYellow, pattern, common.ColorReset)
+		return nil
+	}
+
+	imported := 0
+	for _, tag := range tags {
+		name := strings.TrimPrefix(tag, stripPrefix)
+		if name == "" {
+			fmt.Printf("%sWarning: Skipping tag '%s', stripping the prefix leaves an empty name%s\n", common.ColorYellow, tag, common.ColorReset)
+			continue
+		}
+
+		if _, err := common.GetBookmarkReference(bookmarksDir, name); err == nil {
+			fmt.Printf("%sWarning: Skipping tag '%s', bookmark '%s' already exists%s\n", common.ColorYellow, tag, name, common.ColorReset)
+			continue
+		}
+
+		if err := writeBookmark(name, "refs/tags/"+tag, time.Time{}); err != nil {
+			fmt.Printf("%sWarning: Failed to import tag '%s': %v%s\n", common.ColorYellow, tag, err, common.ColorReset)
+			continue
+		}
+		fmt.Printf("%s  %s -> %s%s\n", common.ColorWhite, name, tag, common.ColorReset)
+		imported++
+	}
+
+	fmt.Printf("%s✅ Imported %d bookmark(s) from tags%s\n", common.ColorGreen, imported, common.ColorReset)
+	return nil
+}
+
+// gcBookmarks groups bookmarks storing the same reference and deletes the duplicates. Bookmarks
+// that only resolve to the same commit today, like HEAD~2 and a hash, are not duplicates.
+// In each group, the bookmark named in keep survives, otherwise the most recently written one.
+func gcBookmarks(keep []string, dryRun bool) error {
+	bookmarks, err := getBookmarkNames()
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[string][]string)
+	aliasTargets := make(map[string]bool)
+	var references []string
+	for _, name := range bookmarks {
+		// An alias resolves to the commit of its target without duplicating it
+		content, err := common.ReadBookmarkFile(bookmarksDir, name)
+		if err != nil {
+			continue
+		}
+		if target, isAlias := strings.CutPrefix(content, common.BookmarkAliasPrefix); isAlias {
+			aliasTargets[target] = true
+			continue
+		}
+
+		if _, ok := groups[content]; !ok {
+			references = append(references, content)
+		}
+		groups[content] = append(groups[content], name)
+	}
+	sort.Strings(references)
+
+	duplicates := 0
+	deleted := 0
+	for _, reference := range references {
+		names := groups[reference]
+		if len(names) < 2 {
+			continue
+		}
+		duplicates++
+		sort.Strings(names)
+
+		survivor := pickSurvivor(bookmarksDir, names, keep, aliasTargets)
+		fmt.Printf("%s%s%s\n", common.ColorYellow, reference, common.ColorReset)
+		for _, name := range names {
+			if name == survivor {
+				fmt.Printf("%s  %s (kept)%s\n", common.ColorWhite, name, common.ColorReset)
+				continue
+			}
+			// Deleting it would leave its aliases dangling
+			if aliasTargets[name] {
+				fmt.Printf("%s  %s (kept, an alias points to it)%s\n", common.ColorWhite, name, common.ColorReset)
+				continue
+			}
+			if dryRun {
+				fmt.Printf("%s  %s (would delete)%s\n", common.ColorWhite, name, common.ColorReset)
+				continue
+			}
+			if err := os.Remove(filepath.Join(bookmarksDir, name)); err != nil {
+				fmt.Printf("%s  %s (failed to delete: %v)%s\n", common.ColorRed, name, err, common.ColorReset)
+				continue
+			}
+			fmt.Printf("%s  %s (deleted)%s\n", common.ColorWhite, name, common.ColorReset)
+			deleted++
+		}
+	}
+
+	if duplicates == 0 {
+		fmt.Printf("%sNo duplicate bookmarks found%s\n", common.ColorGreen, common.ColorReset)
+	} else if dryRun {
+		fmt.Printf("%sRun 'git bookmark gc --force' to delete them%s\n", common.ColorCyan, common.ColorReset)
+	} else {
+		fmt.Printf("%s✅ Deleted %d duplicate bookmark(s)%s\n", common.ColorGreen, deleted, common.ColorReset)
+	}
+	return nil
+}
+
+// pickSurvivor returns the bookmark of a duplicate group that gc keeps: a --keep one, then one
+// an alias points to, then the newest
+func pickSurvivor(bookmarksDir string, names []string, keep []string, aliasTargets map[string]bool) string {
+	for _, name := range names {
+		for _, kept := range keep {
+			if name == kept {
+				return name
+			}
+		}
+	}
+	for _, name := range names {
+		if aliasTargets[name] {
+			return name
+		}
+	}
+
+	survivor := names[0]
+	var newest int64
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(bookmarksDir, name))
+		if err != nil {
+			continue
+		}
+		if modTime := info.ModTime().UnixNano(); modTime > newest {
+			newest = modTime
+			survivor = name
+		}
+	}
+	return survivor
+}
+
+// pruneExpiredBookmarks deletes the bookmarks whose TTL ran out
+func pruneExpiredBookmarks(dryRun bool) error {
+	bookmarks, err := getBookmarkNames()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	pruned := 0
+	for _, name := range bookmarks {
+		expires, hasExpiry, err := common.GetBookmarkExpiry(bookmarksDir, name)
+		if err != nil {
+			fmt.Printf("%sWarning: %v%s\n", common.ColorYellow, err, common.ColorReset)
+			continue
+		}
+		if !hasExpiry || expires.After(now) {
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("%s  %s (would delete, expired %s)%s\n", common.ColorWhite, name, expires.Local().Format("2006-01-02 15:04"), common.ColorReset)
+			pruned++
+			continue
+		}
+		if err := os.Remove(filepath.Join(bookmarksDir, name)); err != nil {
+			fmt.Printf("%s  %s (failed to delete: %v)%s\n", common.ColorRed, name, err, common.ColorReset)
+			continue
+		}
+		fmt.Printf("%s  %s (deleted, expired %s)%s\n", common.ColorWhite, name, expires.Local().Format("2006-01-02 15:04"), common.ColorReset)
+		pruned++
+	}
+
+	if pruned == 0 {
+		fmt.Printf("%sNo expired bookmarks found%s\n", common.ColorGreen, common.ColorReset)
+	} else if !dryRun {
+		fmt.Printf("%s✅ Deleted %d expired bookmark(s)%s\n", common.ColorGreen, pruned, common.ColorReset)
+	}
+	return nil
 }
 
 func updatePreviousBookmark(currentBookmark string) error {
@@ -445,6 +1153,90 @@ func updatePreviousBookmark(currentBookmark string) error {
 	return nil
 }
 
+func getBookmarkHistoryFile() (string, error) {
+	gitDir, err := common.GetGitDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "BOOKMARK_HISTORY"), nil
+}
+
+// readBookmarkHistory returns the checked out bookmarks, most recent first
+func readBookmarkHistory() ([]string, error) {
+	historyFile, err := getBookmarkHistoryFile()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(historyFile)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmark history: %v", err)
+	}
+
+	var history []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			history = append(history, line)
+		}
+	}
+	return history, nil
+}
+
+// recordBookmarkHistory moves the bookmark to the top of the history, dropping the oldest entries
+// past bookmarkHistoryLimit
+func recordBookmarkHistory(name string) error {
+	history, err := readBookmarkHistory()
+	if err != nil {
+		return err
+	}
+
+	updated := []string{name}
+	for _, entry := range history {
+		if entry != name && len(updated) < bookmarkHistoryLimit {
+			updated = append(updated, entry)
+		}
+	}
+
+	historyFile, err := getBookmarkHistoryFile()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(historyFile, []byte(strings.Join(updated, "\n")+"\n"), 0644)
+}
+
+// listRecentBookmarks shows the last checked out bookmarks with the commit they resolve to now
+func listRecentBookmarks(count int) error {
+	history, err := readBookmarkHistory()
+	if err != nil {
+		return err
+	}
+
+	if len(history) == 0 {
+		fmt.Printf("%sNo bookmark was checked out yet%s\n", common.ColorYellow, common.ColorReset)
+		return nil
+	}
+
+	fmt.Printf("%sRecent bookmarks:%s\n", common.ColorCyan, common.ColorReset)
+	for i, name := range history[:min(count, len(history))] {
+		reference, err := common.ResolveBookmarkExpression(bookmarksDir, name)
+		if err != nil {
+			fmt.Printf("%s  %d. %s %s(%v)%s\n", common.ColorWhite, i+1, name, common.ColorRed, err, common.ColorReset)
+			continue
+		}
+
+		commitHash, err := common.GetCommitHash(reference)
+		if err != nil {
+			fmt.Printf("%s  %d. %s -> %s%s\n", common.ColorWhite, i+1, name, reference, common.ColorReset)
+		} else {
+			fmt.Printf("%s  %d. %s -> %s %s(%s)%s\n", common.ColorWhite, i+1, name, reference, common.ColorYellow, commitHash[:8], common.ColorReset)
+		}
+	}
+	return nil
+}
+
 func getPreviousBookmark() (string, error) {
 	gitDir, err := common.GetGitDirectory()
 	if err != nil {
@@ -472,32 +1264,71 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Actions:")
 	fmt.Println("  create <name> [reference]  Create a bookmark pointing to a reference (default: current branch/HEAD)")
-	fmt.Println("  delete <name>              Delete a bookmark")
+	fmt.Println("  alias <name> <bookmark>    Create a bookmark that follows another bookmark")
+	fmt.Println("  delete <name|glob>         Delete a bookmark, or all bookmarks matching a glob")
 	fmt.Println("  show <name>                Show what a bookmark points to")
 	fmt.Println("  list                       List all bookmarks")
 	fmt.Println("  checkout <name>            Checkout a bookmark")
 	fmt.Println("  -                          Checkout the previous bookmark")
 	fmt.Println("  interactive                Interactive bookmark selection menu")
 	fmt.Println("  sync <name>                Create/update branch to point to bookmark's commit")
+	fmt.Println("  touch <name>               Move an existing bookmark to the current branch/HEAD")
+	fmt.Println("  import-tags                Create a bookmark for each tag (filter with --pattern)")
+	fmt.Println("  verify                     Check that bookmarks resolve and are on a branch")
+	fmt.Println("  recent [n]                 List the last n checked out bookmarks (default: 10)")
+	fmt.Println("  stats                      Count the bookmarks that resolve, are broken, or are reachable from HEAD")
+	fmt.Println("  gc                         Report bookmarks storing the same reference (delete all but the newest with --force)")
+	fmt.Println("  prune --expired            Delete the bookmarks whose --ttl ran out")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -n, --name <name>          Specify bookmark name (alternative to positional arg)")
 	fmt.Println("  -a, --absolute             Show absolute commit hash instead of reference (for show)")
+	fmt.Println("  -q, --quiet                Suppress non-error output (for checkout)")
+	fmt.Println("  --create-if-missing        Create a missing bookmark at the current branch/HEAD instead of failing")
+	fmt.Println("                             (for checkout, asked interactively otherwise)")
+	fmt.Println("  --as-branch <name>         Create a branch at the bookmark and check it out (for checkout)")
+	fmt.Println("  --auto-name                Suffix the --as-branch name (name-2, name-3...) when it is taken")
+	fmt.Println("  --autostash                Stash local changes before the checkout and restore them after")
+	fmt.Println("  -y, --yes                  Don't ask before deleting bookmarks matching a glob (for delete)")
+	fmt.Println("  --pattern <glob>           Only import tags matching the glob (for import-tags)")
+	fmt.Println("  --strip-prefix <prefix>    Remove the prefix from tag names (for import-tags)")
+	fmt.Println("  --json                     Print the stats as JSON (for stats)")
+	fmt.Println("  --ttl <duration>           Make the bookmark expire after a duration like 12h or 7d (for create)")
+	fmt.Println("  --dry-run                  Only report expired bookmarks (for prune)")
+	fmt.Println("  -f, --force                Delete the duplicate bookmarks instead of reporting them (for gc)")
+	fmt.Println("  --keep <name>              Keep this bookmark in its duplicate group (for gc, repeatable)")
 	fmt.Println("  -h, --help                 Show this help message")
+	fmt.Println("  --version                  Show the version of the tool and git")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  git-bookmark create fixes HEAD~2       # Create bookmark 'fixes' pointing to HEAD~2")
 	fmt.Println("  git-bookmark create stable main        # Create bookmark 'stable' pointing to main branch")
+	fmt.Println("  git-bookmark alias latest fixes        # 'latest' resolves to wherever 'fixes' points")
 	fmt.Println("  git-bookmark list                      # List all bookmarks")
 	fmt.Println("  git-bookmark checkout fixes            # Checkout the 'fixes' bookmark")
+	fmt.Println("  git-bookmark checkout fixes --quiet    # Checkout 'fixes' without any output")
+	fmt.Println("  git-bookmark checkout fixes --as-branch fix --auto-name")
+	fmt.Println("                                         # Work on 'fixes' in a new branch, even if 'fix' is taken")
+	fmt.Println("  git-bookmark checkout fixes~2          # Checkout two commits before 'fixes'")
+	fmt.Println("  git-bookmark delete 'review/*' --yes   # Delete all bookmarks under review/")
 	fmt.Println("  git-bookmark show fixes --absolute     # Show absolute commit hash for 'fixes'")
 	fmt.Println("  git-bookmark -                         # Checkout previous bookmark")
 	fmt.Println("  git-bookmark interactive               # Interactive bookmark selection")
+	fmt.Println("  git-bookmark recent 5                  # The last 5 bookmarks checked out")
 	fmt.Println("  git-bookmark sync fixes                # Create/update 'fixes' branch to bookmark's commit")
+	fmt.Println("  git-bookmark touch fixes               # Move 'fixes' to where you are now")
+	fmt.Println("  git-bookmark import-tags --pattern 'release/*' --strip-prefix release/")
+	fmt.Println("                                         # Bookmark each release tag by version")
+	fmt.Println("  git-bookmark verify                    # Audit bookmarks after a rebase")
+	fmt.Println("  git-bookmark gc                        # Show bookmarks storing the same reference")
+	fmt.Println("  git-bookmark gc --force --keep stable  # Delete them, keeping 'stable' in its group")
+	fmt.Println("  git-bookmark create scratch --ttl 2d   # Bookmark the current branch for two days")
 	fmt.Println()
 	fmt.Println("Notes:")
 	fmt.Println("  - Bookmarks store relative references (e.g., HEAD~2) and resolve them when used")
-	fmt.Println("  - Bookmarks are stored in .git/bookmarks/")
+	fmt.Println("  - Bookmarks are stored in .git/bookmarks/, or in the directory set by GIT_TOOLS_BOOKMARK_DIR")
+	fmt.Println("    or 'git config git-tools.bookmark.dir'")
 	fmt.Println("  - Use 'git-bookmark -' to quickly switch between bookmarks")
 	fmt.Println("  - sync creates the branch if it doesn't exist, or updates it if it does")
+	fmt.Println("  - Expired bookmarks are flagged by list and can still be checked out until pruned")
 }
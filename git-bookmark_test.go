@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"git-tools/common"
+	"git-tools/common/testutil"
+)
+
+// newTestRepo creates a repository with testutil.NewRepo and points the bookmarks to its
+// .git/bookmarks
+func newTestRepo(t *testing.T) {
+	t.Helper()
+	t.Setenv("GIT_TOOLS_BOOKMARK_DIR", "")
+	testutil.NewRepo(t)
+	if err := setBookmarksDir(&common.Config{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFailedCheckoutKeepsPreviousBookmark(t *testing.T) {
+	newTestRepo(t)
+	testutil.WriteFile(t, "file", "main\n")
+	testutil.Git(t, "commit", "-q", "-m", "main")
+	testutil.Git(t, "checkout", "-q", "-b", "other")
+	testutil.WriteFile(t, "file", "other\n")
+	testutil.Git(t, "commit", "-q", "-m", "other")
+	testutil.Git(t, "checkout", "-q", "main")
+
+	if err := writeBookmark("first", "main", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeBookmark("second", "other", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkoutBookmark("first", true, false, "", false); err != nil {
+		t.Fatalf("checkout of 'first': %v", err)
+	}
+
+	// A local change to a file that differs on 'other' makes git refuse the checkout
+	if err := os.WriteFile("file", []byte("local change\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkoutBookmark("second", true, false, "", false); err == nil {
+		t.Fatalf("checkout of 'second' succeeded over a conflicting local change")
+	}
+
+	previous, err := getPreviousBookmark()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous != "first" {
+		t.Errorf("PREVIOUS_BOOKMARK = %q after a failed checkout, want first", previous)
+	}
+}
+
+func TestGcOnlyGroupsIdenticalReferences(t *testing.T) {
+	newTestRepo(t)
+	testutil.Commit(t, "first")
+	second := testutil.Commit(t, "second")
+	testutil.Commit(t, "third")
+	testutil.Git(t, "checkout", "-q", second)
+
+	// All three resolve to the same commit now, but only the HEAD~1 ones will keep doing so
+	for name, reference := range map[string]string{"relative": "HEAD~1", "again": "HEAD~1", "hash": second} {
+		if err := writeBookmark(name, reference, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := gcBookmarks(nil, true); err != nil {
+		t.Fatalf("gc without --force: %v", err)
+	}
+	if names, _ := getBookmarkNames(); len(names) != 3 {
+		t.Fatalf("gc without --force left %v, want the three bookmarks", names)
+	}
+
+	if err := gcBookmarks([]string{"again"}, false); err != nil {
+		t.Fatalf("gc --force: %v", err)
+	}
+	names, err := getBookmarkNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "again,hash" {
+		t.Errorf("gc --force left %v, want again and hash", names)
+	}
+}
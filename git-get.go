@@ -1,8 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"git-tools/common"
 )
@@ -11,22 +16,68 @@ type getOptions struct {
 	subcommand    string
 	remote        string
 	includeRemote bool
+	short         bool
+	null          bool
+	filter        string
+	verbose       bool
+	count         bool
+	json          bool
+	format        string
+	prefix        string
+	invert        bool
+	nameOnly      bool
+	body          bool
+	full          bool
+	dirty         bool
+	failIfAny     bool
+	args          []string
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		common.PrintVersion("git-get")
+		os.Exit(0)
+	}
+
+	// -C/--repo runs the command in another repository, like git -C
+	if len(os.Args) > 1 && (os.Args[1] == "-C" || os.Args[1] == "--repo") {
+		if len(os.Args) < 3 {
+			fmt.Fprintf(os.Stderr, "%sError: missing argument for %s%s\n", common.ColorRed, os.Args[1], common.ColorReset)
+			os.Exit(1)
+		}
+		if err := os.Chdir(os.Args[2]); err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: cannot change to '%s': %v%s\n", common.ColorRed, os.Args[2], err, common.ColorReset)
+			os.Exit(1)
+		}
+		os.Args = append(os.Args[:1], os.Args[3:]...)
+	}
+
 	if !common.IsGitRepository() {
 		fmt.Fprintf(os.Stderr, "%sError: This directory is not a git repository.%s\n", common.ColorRed, common.ColorReset)
 		os.Exit(1)
 	}
 
-	opts, err := parseArgs()
+	cfg, err := common.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+
+	opts, err := parseArgs(cfg)
 
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
 
-	if opts.subcommand == "main-branch" {
+	// Status checks need a work tree, the other subcommands only query refs
+	if (opts.subcommand == "conflicts" || opts.subcommand == "status" || opts.subcommand == "summary") && common.IsBareRepository() {
+		fmt.Fprintf(os.Stderr, "%sError: %s requires a work tree and cannot run in a bare repository%s\n", common.ColorRed, opts.subcommand, common.ColorReset)
+		os.Exit(1)
+	}
+
+	switch opts.subcommand {
+	case "main-branch":
 		name, err := common.GetRemoteMainBranch(opts.remote)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
@@ -37,13 +88,530 @@ func main() {
 			fmt.Printf("%s/", opts.remote)
 		}
 		fmt.Println(name)
+	case "merge-base":
+		b := "HEAD"
+		if len(opts.args) > 1 {
+			b = opts.args[1]
+		}
+		base, err := common.MergeBase(opts.args[0], b)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+
+		if opts.short {
+			base, err = common.GetShortCommitHash(base)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+				os.Exit(1)
+			}
+		}
+		fmt.Println(base)
+	case "fork-point":
+		upstream := ""
+		if len(opts.args) > 0 {
+			upstream = opts.args[0]
+		} else {
+			var err error
+			if upstream, err = common.GetUpstream(""); err != nil {
+				fmt.Fprintf(os.Stderr, "%sError: the current branch has no upstream, pass one explicitly%s\n", common.ColorRed, common.ColorReset)
+				os.Exit(1)
+			}
+		}
+		if !common.GitRefExists(upstream) {
+			fmt.Fprintf(os.Stderr, "%sError: reference '%s' does not exist%s\n", common.ColorRed, upstream, common.ColorReset)
+			os.Exit(1)
+		}
+		base, err := common.ForkPoint(upstream)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+
+		if opts.short {
+			base, err = common.GetShortCommitHash(base)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+				os.Exit(1)
+			}
+		}
+		fmt.Println(base)
+	case "push-target":
+		branch := ""
+		if len(opts.args) > 0 {
+			branch = opts.args[0]
+		}
+		target, err := common.GetPushTarget(branch)
+		if err != nil {
+			if branch == "" {
+				branch = "the current branch"
+			}
+			fmt.Fprintf(os.Stderr, "%sError: %s has no push target%s\n", common.ColorRed, branch, common.ColorReset)
+			os.Exit(1)
+		}
+		fmt.Println(target)
+	case "files-changed":
+		head := "HEAD"
+		if len(opts.args) > 1 {
+			head = opts.args[1]
+		}
+		files, err := common.ChangedFiles(opts.args[0], head)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+
+		if opts.filter != "" {
+			files, err = filterPaths(files, opts.filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+				os.Exit(1)
+			}
+		}
+		printList(files, opts.null)
+	case "hash":
+		ref := "HEAD"
+		if len(opts.args) > 0 {
+			ref = opts.args[0]
+		}
+
+		hash, err := common.ResolveCommit(ref)
+		if err == nil && opts.short {
+			hash, err = common.GetShortCommitHash(hash)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: cannot resolve '%s'%s\n", common.ColorRed, ref, common.ColorReset)
+			os.Exit(1)
+		}
+		fmt.Println(hash)
+	case "version":
+		describeOpts := common.DescribeOptions{Dirty: opts.dirty}
+		if len(opts.args) > 0 {
+			describeOpts.Ref = opts.args[0]
+		}
+		version, err := common.Describe(describeOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		fmt.Println(version)
+	case "message":
+		ref := "HEAD"
+		if len(opts.args) > 0 {
+			ref = opts.args[0]
+		}
+
+		getMessage := common.GetCommitMessage
+		if opts.body {
+			getMessage = common.GetCommitBody
+		} else if opts.full {
+			getMessage = common.GetCommitFullMessage
+		}
+		message, err := getMessage(ref)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: cannot resolve '%s'%s\n", common.ColorRed, ref, common.ColorReset)
+			os.Exit(1)
+		}
+		// An empty body prints nothing rather than a blank line
+		if message != "" {
+			fmt.Println(message)
+		}
+	case "author", "committer":
+		ref := "HEAD"
+		if len(opts.args) > 0 {
+			ref = opts.args[0]
+		}
+
+		format := opts.format
+		if format == "" && opts.subcommand == "author" {
+			format = "%an <%ae>"
+		} else if format == "" {
+			format = "%cn <%ce>"
+		}
+
+		value, err := common.CommitField(ref, format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: cannot resolve '%s'%s\n", common.ColorRed, ref, common.ColorReset)
+			os.Exit(1)
+		}
+		fmt.Println(value)
+	case "conflicts":
+		files, err := common.ConflictedFiles()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		printList(files, opts.null)
+	case "rev-parse":
+		output, exitCode, err := common.RevParse(opts.args...)
+		fmt.Print(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(exitCode)
+		}
+	case "status":
+		count, err := countStatus(opts.args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(2)
+		}
+		if opts.count {
+			fmt.Println(count)
+		}
+		if count == 0 {
+			os.Exit(1)
+		}
+	case "worktrees":
+		worktrees, err := common.ListWorktrees()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		printWorktrees(worktrees, opts.json)
+	case "remotes":
+		remotes, err := common.ListRemotes()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		printRemotes(remotes, opts.json, opts.nameOnly)
+	case "summary":
+		summary, err := common.GetStatusSummary()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		printSummary(summary, opts.json)
+	case "env":
+		for _, variable := range collectEnv(opts.remote) {
+			fmt.Printf("export %s%s=%s\n", opts.prefix, variable[0], common.ShellQuote(variable[1]))
+		}
+	case "detached":
+		detached, err := common.IsDetachedHead()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(2)
+		}
+		if opts.verbose {
+			if detached {
+				fmt.Println("detached")
+			} else {
+				fmt.Println("on branch")
+			}
+		}
+		if detached == opts.invert {
+			os.Exit(1)
+		}
+	case "operation":
+		fmt.Println(common.InProgressOperation())
+	case "stash-count":
+		stashes, err := common.ListStashes()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(2)
+		}
+		fmt.Println(len(stashes))
+		if opts.failIfAny && len(stashes) > 0 {
+			os.Exit(1)
+		}
+	case "log-since-tag":
+		ref := "HEAD"
+		if len(opts.args) > 0 {
+			ref = opts.args[0]
+		}
+		printLogSinceTag(ref, opts.format, opts.json)
+	case "full-ref":
+		fullName, err := common.FullRefName(opts.args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		fmt.Println(fullName)
+	case "is-merged":
+		into := mergeTarget(opts)
+		if !common.IsBranch(opts.args[0]) {
+			fmt.Fprintf(os.Stderr, "%sError: branch '%s' does not exist%s\n", common.ColorRed, opts.args[0], common.ColorReset)
+			os.Exit(1)
+		}
+		merged, err := common.IsAncestor(opts.args[0], into)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		if opts.verbose {
+			if merged {
+				fmt.Printf("branch '%s' is merged into '%s'\n", opts.args[0], into)
+			} else {
+				fmt.Printf("branch '%s' is not merged into '%s'\n", opts.args[0], into)
+			}
+		}
+		if !merged {
+			os.Exit(1)
+		}
+	case "merged-branches":
+		into := mergeTarget(opts)
+		branches, err := common.GetLocalBranches()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		var merged []string
+		for _, branch := range branches {
+			if branch == into {
+				continue
+			}
+			if isMerged, err := common.IsAncestor(branch, into); err == nil && isMerged {
+				merged = append(merged, branch)
+			}
+		}
+		printList(merged, opts.null)
+	case "ref-type":
+		fmt.Println(common.ClassifyRef(opts.args[0]))
+	case "ref-exists":
+		exitWithCheck(common.GitRefExists(opts.args[0]), fmt.Sprintf("reference '%s'", opts.args[0]), opts.verbose)
+	case "branch-exists":
+		exitWithCheck(common.IsBranch(opts.args[0]), fmt.Sprintf("branch '%s'", opts.args[0]), opts.verbose)
+	}
+}
+
+// revParseBlockedOptions are rev-parse options that point git at another repository or
+// do something other than querying it. They are refused to keep the passthrough read-only.
+var revParseBlockedOptions = []string{"--git-dir=", "--work-tree=", "--namespace=", "--parseopt", "--sq-quote", "--local-env-vars"}
+
+// checkRevParseArgs refuses arguments that are not safe to forward to git rev-parse
+func checkRevParseArgs(args []string) error {
+	for _, arg := range args {
+		if arg == "--" {
+			return nil
+		}
+		for _, blocked := range revParseBlockedOptions {
+			if arg == blocked || (strings.HasSuffix(blocked, "=") && strings.HasPrefix(arg, blocked)) {
+				return fmt.Errorf("rev-parse option not allowed: %s", arg)
+			}
+		}
+	}
+	return nil
+}
+
+// countStatus counts the files in the given state: staged, unstaged, conflicted or dirty
+func countStatus(kind string) (int, error) {
+	switch kind {
+	case "staged":
+		return common.CountStagedChanges()
+	case "unstaged":
+		return common.CountUnstagedChanges()
+	case "conflicted":
+		files, err := common.ConflictedFiles()
+		return len(files), err
+	default:
+		return common.CountUncommittedChanges()
+	}
+}
+
+// printWorktrees prints the path and branch of each worktree, or all their details as JSON
+func printWorktrees(worktrees []common.Worktree, asJSON bool) {
+	if asJSON {
+		if worktrees == nil {
+			worktrees = []common.Worktree{}
+		}
+		output, _ := json.MarshalIndent(worktrees, "", "  ")
+		fmt.Println(string(output))
+		return
+	}
+
+	for _, worktree := range worktrees {
+		branch := worktree.Branch
+		if worktree.Bare {
+			branch = "(bare)"
+		} else if worktree.Detached {
+			branch = "(detached)"
+		}
+		fmt.Printf("%s\t%s\n", worktree.Path, branch)
+	}
+}
+
+func printRemotes(remotes []common.Remote, asJSON, nameOnly bool) {
+	if asJSON {
+		if remotes == nil {
+			remotes = []common.Remote{}
+		}
+		output, _ := json.MarshalIndent(remotes, "", "  ")
+		fmt.Println(string(output))
+		return
+	}
+
+	for _, remote := range remotes {
+		if nameOnly {
+			fmt.Println(remote.Name)
+		} else {
+			fmt.Printf("%s\t%s\n", remote.Name, remote.FetchURL)
+		}
+	}
+}
+
+// printLogSinceTag lists the commits between the last tag reachable from ref and ref
+func printLogSinceTag(ref, format string, asJSON bool) {
+	tag, err := common.GetLastTag(ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+	revRange := tag + ".." + ref
+
+	if asJSON {
+		entries, err := common.GetLog(revRange)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: cannot list commits of '%s'%s\n", common.ColorRed, revRange, common.ColorReset)
+			os.Exit(1)
+		}
+		if entries == nil {
+			entries = []common.LogEntry{}
+		}
+		output, _ := json.MarshalIndent(entries, "", "  ")
+		fmt.Println(string(output))
+		return
+	}
+
+	if format == "" {
+		format = "%h %s"
+	}
+	output, err := common.FormatLog(revRange, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: cannot list commits of '%s'%s\n", common.ColorRed, revRange, common.ColorReset)
+		os.Exit(1)
 	}
+	fmt.Print(output)
 }
 
-func parseArgs() (*getOptions, error) {
+// printSummary prints the status summary as JSON, or as one 'name value' line per field
+func printSummary(summary *common.StatusSummary, asJSON bool) {
+	if asJSON {
+		output, _ := json.MarshalIndent(summary, "", "  ")
+		fmt.Println(string(output))
+		return
+	}
+
+	fmt.Printf("branch %s\n", summary.Branch)
+	fmt.Printf("upstream %s\n", summary.Upstream)
+	fmt.Printf("ahead %d\n", summary.Ahead)
+	fmt.Printf("behind %d\n", summary.Behind)
+	fmt.Printf("staged %d\n", summary.Staged)
+	fmt.Printf("unstaged %d\n", summary.Unstaged)
+	fmt.Printf("conflicts %d\n", summary.Conflicts)
+	fmt.Printf("dirty %t\n", summary.Dirty)
+}
+
+// collectEnv gathers the repository state for prompts as name/value pairs. Values that
+// don't apply, like the upstream of a detached HEAD, are left empty.
+func collectEnv(remote string) [][2]string {
+	var branch, head, upstream, ahead, behind, main, dirty string
+
+	branch, _ = common.GetCurrentBranch()
+	head, _ = common.GetShortCommitHash("HEAD")
+	if branch != "" {
+		upstream, _ = common.GetUpstream(branch)
+	}
+	if upstream != "" {
+		if aheadCount, behindCount, err := common.AheadBehind(upstream, "HEAD"); err == nil {
+			ahead, behind = strconv.Itoa(aheadCount), strconv.Itoa(behindCount)
+		}
+	}
+	if name, err := common.GetRemoteMainBranch(remote); err == nil {
+		main = remote + "/" + name
+	}
+	if !common.IsBareRepository() {
+		if count, err := common.CountUncommittedChanges(); err == nil {
+			dirty = strconv.Itoa(count)
+		}
+	}
+
+	return [][2]string{
+		{"BRANCH", branch},
+		{"HEAD", head},
+		{"UPSTREAM", upstream},
+		{"AHEAD", ahead},
+		{"BEHIND", behind},
+		{"MAIN", main},
+		{"DIRTY", dirty},
+	}
+}
+
+// envPrefixPattern matches prefixes that make valid shell variable names
+var envPrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// mergeTarget returns the branch is-merged and merged-branches check against: the argument
+// after the branch if given, then the remote's main branch, then the local default branch
+func mergeTarget(opts *getOptions) string {
+	position := 0
+	if opts.subcommand == "is-merged" {
+		position = 1
+	}
+	if len(opts.args) > position {
+		into := opts.args[position]
+		if !common.GitRefExists(into) {
+			fmt.Fprintf(os.Stderr, "%sError: reference '%s' does not exist%s\n", common.ColorRed, into, common.ColorReset)
+			os.Exit(1)
+		}
+		return into
+	}
+
+	if name, err := common.GetRemoteMainBranch(opts.remote); err == nil {
+		return fmt.Sprintf("%s/%s", opts.remote, name)
+	}
+	name, err := common.LocalDefaultBranch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %v, pass the branch to check against explicitly%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+	return name
+}
+
+// exitWithCheck exits with 0 if the check passed and 1 otherwise, describing the result when verbose
+func exitWithCheck(exists bool, subject string, verbose bool) {
+	if exists {
+		if verbose {
+			fmt.Printf("%s exists\n", subject)
+		}
+		os.Exit(0)
+	}
+	if verbose {
+		fmt.Printf("%s does not exist\n", subject)
+	}
+	os.Exit(1)
+}
+
+// filterPaths keeps the paths whose full path or file name matches the glob pattern
+func filterPaths(paths []string, pattern string) ([]string, error) {
+	var matched []string
+	for _, path := range paths {
+		fullMatch, err := filepath.Match(pattern, path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter pattern: %s", pattern)
+		}
+		nameMatch, _ := filepath.Match(pattern, filepath.Base(path))
+		if fullMatch || nameMatch {
+			matched = append(matched, path)
+		}
+	}
+	return matched, nil
+}
+
+// printList prints one item per line, or NUL-terminated items when null is set
+func printList(items []string, null bool) {
+	for _, item := range items {
+		if null {
+			fmt.Printf("%s\x00", item)
+		} else {
+			fmt.Println(item)
+		}
+	}
+}
+
+func parseArgs(cfg *common.Config) (*getOptions, error) {
 	opts := &getOptions{
-		remote:        "origin",
+		remote:        cfg.Remote,
 		includeRemote: false,
+		prefix:        "GIT_TOOLS_",
 	}
 	args := os.Args[1:]
 	if len(args) == 0 {
@@ -57,14 +625,24 @@ func parseArgs() (*getOptions, error) {
 		os.Exit(0)
 	}
 
-	// There's only one subcommand for now.
-	if args[0] != "main-branch" {
+	switch args[0] {
+	case "main-branch", "merge-base", "files-changed", "conflicts", "ref-exists", "branch-exists", "hash", "rev-parse", "status", "worktrees", "author", "committer", "env", "detached", "ref-type", "log-since-tag", "fork-point", "summary", "full-ref", "is-merged", "merged-branches", "remotes", "message", "version", "push-target", "stash-count", "operation":
+	default:
 		return nil, fmt.Errorf("unknown subcommand: %s", args[0])
 	}
 
 	opts.subcommand = args[0]
 	args = args[1:]
 
+	// rev-parse arguments are forwarded as-is to git
+	if opts.subcommand == "rev-parse" {
+		if err := checkRevParseArgs(args); err != nil {
+			return nil, err
+		}
+		opts.args = args
+		return opts, nil
+	}
+
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		switch arg {
@@ -76,21 +654,173 @@ func parseArgs() (*getOptions, error) {
 			i++
 		case "--include-remote", "-i":
 			opts.includeRemote = true
+		case "--short", "-s":
+			opts.short = true
+		case "--null", "-z":
+			opts.null = true
+		case "--filter", "-f":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing argument for %s", arg)
+			}
+			opts.filter = args[i+1]
+			i++
+		case "--verbose", "-v":
+			opts.verbose = true
+		case "--count", "-c":
+			opts.count = true
+		case "--json":
+			opts.json = true
+		case "--invert":
+			opts.invert = true
+		case "--fail-if-any":
+			opts.failIfAny = true
+		case "--name-only":
+			opts.nameOnly = true
+		case "--body":
+			opts.body = true
+		case "--full":
+			opts.full = true
+		case "--dirty":
+			opts.dirty = true
+		case "--prefix":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing argument for %s", arg)
+			}
+			opts.prefix = args[i+1]
+			i++
+		case "--format":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing argument for %s", arg)
+			}
+			opts.format = args[i+1]
+			i++
+		case "--help", "-h":
+			printUsage()
+			os.Exit(0)
 		default:
-			return nil, fmt.Errorf("unknown argument: %s", arg)
+			// Unknown options would otherwise be taken for references
+			if strings.HasPrefix(arg, "-") {
+				return nil, fmt.Errorf("unknown option: %s", arg)
+			}
+			opts.args = append(opts.args, arg)
 		}
 
 	}
 
+	if opts.failIfAny && opts.subcommand != "stash-count" {
+		return nil, fmt.Errorf("--fail-if-any can only be used with stash-count")
+	}
+
+	// Validate positional arguments for each subcommand.
+	switch opts.subcommand {
+	case "main-branch", "conflicts", "worktrees", "env", "detached", "summary", "remotes", "stash-count", "operation":
+		if len(opts.args) > 0 {
+			return nil, fmt.Errorf("unknown argument: %s", opts.args[0])
+		}
+		if opts.subcommand == "remotes" && opts.nameOnly && opts.json {
+			return nil, fmt.Errorf("--name-only and --json cannot be combined")
+		}
+		if opts.subcommand == "env" && !envPrefixPattern.MatchString(opts.prefix) {
+			return nil, fmt.Errorf("invalid variable prefix: %s", opts.prefix)
+		}
+	case "merge-base", "files-changed":
+		if len(opts.args) == 0 {
+			return nil, fmt.Errorf("%s requires at least one reference", opts.subcommand)
+		}
+		if len(opts.args) > 2 {
+			return nil, fmt.Errorf("unknown argument: %s", opts.args[2])
+		}
+	case "hash", "author", "committer", "log-since-tag", "fork-point", "merged-branches", "message", "version", "push-target":
+		if len(opts.args) > 1 {
+			return nil, fmt.Errorf("unknown argument: %s", opts.args[1])
+		}
+		if opts.body && opts.full {
+			return nil, fmt.Errorf("--body and --full cannot be combined")
+		}
+		// Only HEAD has a work tree to be dirty
+		if opts.dirty && len(opts.args) > 0 && opts.args[0] != "HEAD" {
+			return nil, fmt.Errorf("--dirty can only be used when describing HEAD")
+		}
+	case "is-merged":
+		if len(opts.args) == 0 {
+			return nil, fmt.Errorf("is-merged requires a branch")
+		}
+		if len(opts.args) > 2 {
+			return nil, fmt.Errorf("unknown argument: %s", opts.args[2])
+		}
+	case "status":
+		if len(opts.args) == 0 {
+			return nil, fmt.Errorf("status requires a kind: staged, unstaged, conflicted or dirty")
+		}
+		if len(opts.args) > 1 {
+			return nil, fmt.Errorf("unknown argument: %s", opts.args[1])
+		}
+		switch opts.args[0] {
+		case "staged", "unstaged", "conflicted", "dirty":
+		default:
+			return nil, fmt.Errorf("unknown status kind: %s", opts.args[0])
+		}
+	case "ref-exists", "branch-exists", "ref-type", "full-ref":
+		if len(opts.args) == 0 {
+			return nil, fmt.Errorf("%s requires a name", opts.subcommand)
+		}
+		if len(opts.args) > 1 {
+			return nil, fmt.Errorf("unknown argument: %s", opts.args[1])
+		}
+	}
+
 	return opts, nil
 }
 
 func printUsage() {
-	fmt.Println("Usage: git-get [subcommand] [options]")
+	fmt.Println("Usage: git-get [-C <path>] [subcommand] [options]")
 	fmt.Println("Subcommands:")
 	fmt.Println("  main-branch       Get the main branch name from the remote")
+	fmt.Println("  merge-base <a> [b]  Get the common ancestor of a and b (default b: HEAD)")
+	fmt.Println("  fork-point [upstream]  Get the commit HEAD forked from upstream (default: the tracking branch)")
+	fmt.Println("  push-target [branch]  Get where git push pushes branch, e.g. fork/main (default: the current branch)")
+	fmt.Println("  files-changed <base> [head]  List files changed between base and head (default head: HEAD)")
+	fmt.Println("  hash [ref]        Get the commit hash of ref (default: HEAD)")
+	fmt.Println("  author [ref]      Get the author of ref as 'name <email>' (default: HEAD)")
+	fmt.Println("  committer [ref]   Get the committer of ref as 'name <email>' (default: HEAD)")
+	fmt.Println("  version [ref]     Describe ref from the last tag, e.g. v1.2.3-5-gabc1234 (default: HEAD)")
+	fmt.Println("  message [ref]     Get the subject of the commit message of ref (default: HEAD)")
+	fmt.Println("  conflicts         List the files with merge conflicts")
+	fmt.Println("  status <kind>     Exit with 0 if there are staged, unstaged, conflicted or dirty files, 1 otherwise")
+	fmt.Println("  operation         Print the operation in progress: rebase, merge, cherry-pick, revert or none")
+	fmt.Println("  detached          Exit with 0 if HEAD is detached, 1 if it is on a branch")
+	fmt.Println("  stash-count       Print the number of stash entries")
+	fmt.Println("  worktrees         List the worktrees with their checked out branch")
+	fmt.Println("  remotes           List the remotes with their fetch URL")
+	fmt.Println("  summary           Print the branch, upstream, ahead/behind counts and file counts in one call")
+	fmt.Println("  env               Print export lines for the branch, upstream, ahead/behind counts, main branch and dirty files")
+	fmt.Println("  log-since-tag [ref]  List the commits since the last tag reachable from ref (default: HEAD)")
+	fmt.Println("  full-ref <ref>    Print the full name of the reference, e.g. refs/heads/main")
+	fmt.Println("  ref-type <ref>    Print branch, remote-branch, tag, commit or unknown")
+	fmt.Println("  is-merged <branch> [into]  Exit with 0 if branch is merged into into (default: the main branch), 1 otherwise")
+	fmt.Println("  merged-branches [into]  List the local branches merged into into (default: the main branch)")
+	fmt.Println("  ref-exists <ref>  Exit with 0 if the reference exists, 1 otherwise")
+	fmt.Println("  branch-exists <name>  Exit with 0 if the local branch exists, 1 otherwise")
+	fmt.Println("  rev-parse <args>...  Run git rev-parse with the given arguments")
 	fmt.Println("Options:")
-	fmt.Println("  --remote, -r      Specify the remote name (default: origin)")
+	fmt.Println("  -C, --repo <path> Run as if started in <path> (must come first)")
+	fmt.Println("  --remote, -r      Specify the remote name (default: git-tools.remote config, or origin)")
 	fmt.Println("  --include-remote, -i Include the remote name in the output")
+	fmt.Println("  --short, -s       Print abbreviated commit hashes")
+	fmt.Println("  --null, -z        Separate list output with NUL characters")
+	fmt.Println("  --filter, -f <glob>  Only list paths matching the glob")
+	fmt.Println("  --verbose, -v     Print the result of existence checks, is-merged and detached")
+	fmt.Println("  --invert          Exit with 0 when HEAD is on a branch instead (for detached)")
+	fmt.Println("  --fail-if-any     Exit with 1 if there are stash entries (for stash-count)")
+	fmt.Println("  --count, -c       Print the number of files (for status)")
+	fmt.Println("  --json            Print the output as JSON (for worktrees, remotes, log-since-tag and summary)")
+	fmt.Println("  --name-only       Only print the remote names (for remotes)")
+	fmt.Println("  --body            Print the body of the message instead of its subject (for message)")
+	fmt.Println("  --full            Print the subject and body of the message (for message)")
+	fmt.Println("  --dirty           Append -dirty if there are uncommitted changes (for version)")
+	fmt.Println("  --prefix <prefix> Prefix of the variable names (for env, default: GIT_TOOLS_)")
+	fmt.Println("  --format <fmt>    Use a git log format instead of 'name <email>' (for author and committer)")
+	fmt.Println("                    or the hash and subject (for log-since-tag)")
 	fmt.Println("  --help, -h        Show this help message")
+	fmt.Println("  --version         Show the version of the tool and git")
 }
@@ -1,28 +1,53 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"git-tools/common"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		common.PrintVersion("git-move-branch")
+		os.Exit(0)
+	}
+
 	if !common.IsGitRepository() {
 		fmt.Fprintf(os.Stderr, "%sError: This directory is not a git repository.%s\n", common.ColorRed, common.ColorReset)
 		os.Exit(1)
 	}
 
-	var branchToMove, newReference string
-	var shouldBackup, shouldCheckout bool
+	cfg, err := common.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+
+	var branchToMove, newReference, upstream, tagOld string
+	var shouldCheckout, shouldSaveUndo, shouldUndo, fastForwardOnly, noCheckout bool
+	shouldBackup := cfg.AutoBackup
 
 	// Parse command line arguments
 	for i := 1; i < len(os.Args); i++ {
 		arg := os.Args[i]
 		if arg == "--backup" {
 			shouldBackup = true
+		} else if arg == "--no-backup" {
+			shouldBackup = false
 		} else if arg == "--checkout" {
 			shouldCheckout = true
+		} else if arg == "--no-checkout" {
+			noCheckout = true
+		} else if arg == "--save-undo" {
+			shouldSaveUndo = true
+		} else if arg == "--undo" {
+			shouldUndo = true
+		} else if arg == "--ff-only" {
+			fastForwardOnly = true
 		} else if arg == "--help" || arg == "-h" {
 			printUsage()
 			os.Exit(0)
@@ -33,6 +58,20 @@ func main() {
 			}
 			i++
 			branchToMove = os.Args[i]
+		} else if arg == "--set-upstream" {
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "%sError: %s requires a remote branch%s\n", common.ColorRed, arg, common.ColorReset)
+				os.Exit(1)
+			}
+			i++
+			upstream = os.Args[i]
+		} else if arg == "--tag-old" {
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "%sError: %s requires a tag name%s\n", common.ColorRed, arg, common.ColorReset)
+				os.Exit(1)
+			}
+			i++
+			tagOld = os.Args[i]
 		} else if arg == "-t" || arg == "--to" {
 			if i+1 >= len(os.Args) {
 				fmt.Fprintf(os.Stderr, "%sError: %s requires a reference%s\n", common.ColorRed, arg, common.ColorReset)
@@ -47,6 +86,41 @@ func main() {
 		}
 	}
 
+	// Load the branch and reference to restore from the saved undo command
+	if shouldUndo {
+		if branchToMove != "" || newReference != "" {
+			fmt.Fprintf(os.Stderr, "%sError: --undo cannot be combined with --branch or --to%s\n", common.ColorRed, common.ColorReset)
+			os.Exit(1)
+		}
+		branchToMove, newReference, err = loadUndo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		fmt.Printf("%sUndoing last move of '%s'%s\n", common.ColorYellow, branchToMove, common.ColorReset)
+	}
+
+	// A target of '-' is read from stdin, e.g. from git-get main-branch -i
+	if newReference == "-" {
+		newReference, err = readReferenceFromStdin()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	}
+
+	if shouldCheckout && noCheckout {
+		fmt.Fprintf(os.Stderr, "%sError: --checkout and --no-checkout cannot be combined%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
+	// A bare repository has nothing to check out
+	isBare := common.IsBareRepository()
+	if isBare && shouldCheckout {
+		fmt.Fprintf(os.Stderr, "%sError: --checkout cannot be used in a bare repository%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
 	// Validate arguments
 	if branchToMove == "" {
 		fmt.Fprintf(os.Stderr, "%sError: Branch name is required. Use -b or --branch to specify the branch to move.%s\n", common.ColorRed, common.ColorReset)
@@ -60,6 +134,24 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Validate the upstream before moving, so a typo doesn't leave the move half done
+	if upstream != "" && !common.GitRefExists(upstream) {
+		fmt.Fprintf(os.Stderr, "%sError: Upstream '%s' does not exist.%s\n", common.ColorRed, upstream, common.ColorReset)
+		os.Exit(1)
+	}
+
+	// Check the tag now, a taken name would otherwise only show once the branch is moved
+	if tagOld != "" {
+		if !common.IsValidRefName("refs/tags/" + tagOld) {
+			fmt.Fprintf(os.Stderr, "%sError: '%s' is not a valid tag name%s\n", common.ColorRed, tagOld, common.ColorReset)
+			os.Exit(1)
+		}
+		if common.IsTag(tagOld) {
+			fmt.Fprintf(os.Stderr, "%sError: Tag '%s' already exists%s\n", common.ColorRed, tagOld, common.ColorReset)
+			os.Exit(1)
+		}
+	}
+
 	// Determine the new reference
 	if newReference != "" {
 		// Validate that the new reference exists
@@ -76,17 +168,6 @@ func main() {
 	fmt.Printf("%sBranch to move: %s%s\n", common.ColorGreen, branchToMove, common.ColorReset)
 	fmt.Printf("%sNew reference:  %s%s\n", common.ColorGreen, newReference, common.ColorReset)
 
-	// Create backup if requested
-	if shouldBackup {
-		fmt.Printf("%s▶️ Creating backup before moving branch...%s\n", common.ColorYellow, common.ColorReset)
-		if err := common.RunGitBackupWithRef(branchToMove); err != nil {
-			fmt.Fprintf(os.Stderr, "%s❌ Failed to create backup: %s%s\n", common.ColorRed, err, common.ColorReset)
-			os.Exit(1)
-		}
-		fmt.Printf("%s✅ Backup created successfully%s\n", common.ColorGreen, common.ColorReset)
-		fmt.Println()
-	}
-
 	// Get current commit of the branch before moving
 	oldCommit, err := common.GetCommitHash(branchToMove)
 	if err != nil {
@@ -101,14 +182,65 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Refuse to rewind or rewrite the branch when only fast-forwards are allowed
+	if fastForwardOnly {
+		if oldCommit == "unknown" {
+			fmt.Fprintf(os.Stderr, "%sError: Cannot check for fast-forward without the current commit of '%s'%s\n", common.ColorRed, branchToMove, common.ColorReset)
+			os.Exit(1)
+		}
+		isFastForward, err := common.IsAncestor(oldCommit, newCommit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: Could not check for fast-forward: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		if !isFastForward {
+			fmt.Fprintf(os.Stderr, "%sError: Moving '%s' to '%s' is not a fast-forward (--ff-only)%s\n", common.ColorRed, branchToMove, newReference, common.ColorReset)
+			os.Exit(1)
+		}
+	}
+
 	// Check if the branch to move is the current branch
 	currentBranch, err := common.GetCurrentBranch()
-	isCurrentBranch := (err == nil && currentBranch == branchToMove)
+	isCurrentBranch := (err == nil && currentBranch == branchToMove && !isBare)
+
+	// Moving the current branch goes through a checkout, which --no-checkout rules out
+	if isCurrentBranch && noCheckout {
+		fmt.Fprintf(os.Stderr, "%sError: '%s' is checked out, it can't be moved without touching the working tree (--no-checkout)%s\n", common.ColorRed, branchToMove, common.ColorReset)
+		fmt.Fprintf(os.Stderr, "%sSwitch to another branch first (e.g. 'git switch --detach'), or drop --no-checkout%s\n", common.ColorYellow, common.ColorReset)
+		os.Exit(1)
+	}
+
+	// Create backup if requested
+	if shouldBackup {
+		fmt.Printf("%s▶️ Creating backup before moving branch...%s\n", common.ColorYellow, common.ColorReset)
+		if err := common.RunGitBackupWithRef(branchToMove, "move-branch"); err != nil {
+			common.LogOperation("move-branch", os.Args[1:], err, common.RefChange{Ref: branchToMove, Before: oldCommit})
+			fmt.Fprintf(os.Stderr, "%s❌ Failed to create backup: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		fmt.Printf("%s✅ Backup created successfully%s\n", common.ColorGreen, common.ColorReset)
+		fmt.Println()
+	}
+
+	// A lightweight tag is a cheaper recovery point than a backup branch
+	if tagOld != "" {
+		if oldCommit == "unknown" {
+			fmt.Fprintf(os.Stderr, "%sError: Cannot tag the old position without the current commit of '%s'%s\n", common.ColorRed, branchToMove, common.ColorReset)
+			os.Exit(1)
+		}
+		fmt.Printf("%s▶️ Tagging the old position of '%s' as '%s'...%s\n", common.ColorYellow, branchToMove, tagOld, common.ColorReset)
+		if err := common.CreateTag(tagOld, oldCommit, false, ""); err != nil {
+			common.LogOperation("move-branch", os.Args[1:], err, common.RefChange{Ref: branchToMove, Before: oldCommit})
+			fmt.Fprintf(os.Stderr, "%s❌ Failed to create tag: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	}
 
 	// If moving the current branch, checkout the target commit first
 	if isCurrentBranch {
 		fmt.Printf("%s▶️ Branch '%s' is currently checked out, switching to target commit first...%s\n", common.ColorYellow, branchToMove, common.ColorReset)
 		if err := common.Checkout(newCommit); err != nil {
+			common.LogOperation("move-branch", os.Args[1:], err, common.RefChange{Ref: branchToMove, Before: oldCommit})
 			fmt.Fprintf(os.Stderr, "%s❌ Failed to checkout target commit: %s%s\n", common.ColorRed, err, common.ColorReset)
 			os.Exit(1)
 		}
@@ -116,7 +248,9 @@ func main() {
 
 	// Move the branch
 	fmt.Printf("%s▶️ Moving branch '%s' to '%s'...%s\n", common.ColorYellow, branchToMove, newReference, common.ColorReset)
-	if err := common.MoveBranch(branchToMove, newReference); err != nil {
+	// Use the resolved commit: a relative target like HEAD~1 changes meaning once the target is checked out
+	if err := common.MoveBranch(branchToMove, newCommit); err != nil {
+		common.LogOperation("move-branch", os.Args[1:], err, common.RefChange{Ref: branchToMove, Before: oldCommit})
 		fmt.Fprintf(os.Stderr, "%s❌ Failed to move branch: %s%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
@@ -131,6 +265,16 @@ func main() {
 	}
 
 	fmt.Printf("%s✅ Branch '%s' moved successfully!%s\n", common.ColorGreen, branchToMove, common.ColorReset)
+	common.LogOperation("move-branch", os.Args[1:], nil, common.RefChange{Ref: branchToMove, Before: oldCommit, After: newCommit})
+
+	if upstream != "" {
+		fmt.Printf("%s▶️ Setting upstream of '%s' to '%s'...%s\n", common.ColorYellow, branchToMove, upstream, common.ColorReset)
+		if err := common.SetUpstream(branchToMove, upstream); err != nil {
+			fmt.Fprintf(os.Stderr, "%s❌ Failed to set upstream: %s%s\n", common.ColorRed, err, common.ColorReset)
+			fmt.Fprintf(os.Stderr, "%sWarning: Branch was moved successfully, but its upstream is unchanged%s\n", common.ColorYellow, common.ColorReset)
+			upstream = ""
+		}
+	}
 
 	// Show summary
 	fmt.Println()
@@ -138,13 +282,90 @@ func main() {
 	fmt.Printf("%s  Branch:       %s%s\n", common.ColorWhite, branchToMove, common.ColorReset)
 	fmt.Printf("%s  From commit:  %s%s\n", common.ColorWhite, oldCommit[:min(8, len(oldCommit))], common.ColorReset)
 	fmt.Printf("%s  To commit:    %s%s\n", common.ColorWhite, newCommit[:min(8, len(newCommit))], common.ColorReset)
-	fmt.Printf("%s  Reference:    %s%s\n", common.ColorWhite, newReference, common.ColorReset)
+	fmt.Printf("%s  Reference:    %s (%s)%s\n", common.ColorWhite, newReference, newCommit[:min(8, len(newCommit))], common.ColorReset)
 	if shouldBackup {
 		fmt.Printf("%s  Backup:       Created%s\n", common.ColorWhite, common.ColorReset)
 	}
+	if tagOld != "" {
+		fmt.Printf("%s  Old tip tag:  %s%s\n", common.ColorWhite, tagOld, common.ColorReset)
+	}
 	if shouldCheckout || isCurrentBranch {
 		fmt.Printf("%s  Checked out:  Yes%s\n", common.ColorWhite, common.ColorReset)
 	}
+	if upstream != "" {
+		fmt.Printf("%s  Upstream:     %s%s\n", common.ColorWhite, upstream, common.ColorReset)
+	}
+
+	if oldCommit != "unknown" {
+		undoCommand := formatUndoCommand(branchToMove, oldCommit)
+		fmt.Println()
+		fmt.Printf("%sTo undo this move, run:%s\n", common.ColorCyan, common.ColorReset)
+		fmt.Printf("%s  %s%s\n", common.ColorWhite, undoCommand, common.ColorReset)
+
+		if shouldSaveUndo {
+			if err := saveUndo(undoCommand); err != nil {
+				fmt.Fprintf(os.Stderr, "%sWarning: Could not save undo command: %s%s\n", common.ColorYellow, err, common.ColorReset)
+			} else {
+				fmt.Printf("%s  (saved, run 'git move-branch --undo' to apply it)%s\n", common.ColorWhite, common.ColorReset)
+			}
+		}
+	}
+}
+
+// formatUndoCommand builds the command that moves the branch back to its old commit
+func formatUndoCommand(branch, oldCommit string) string {
+	return fmt.Sprintf("git move-branch -b %s -t %s", branch, oldCommit)
+}
+
+func getUndoFile() (string, error) {
+	toolsDir, err := common.GetToolsDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(toolsDir, "last-move-undo"), nil
+}
+
+// saveUndo writes the undo command to .git/git-tools/last-move-undo
+func saveUndo(undoCommand string) error {
+	undoFile, err := getUndoFile()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(undoFile, []byte(undoCommand+"\n"), 0644)
+}
+
+// readReferenceFromStdin reads the target reference from the first line of stdin
+func readReferenceFromStdin() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("could not read target reference from stdin")
+	}
+	reference := strings.TrimSpace(line)
+	if reference == "" {
+		return "", fmt.Errorf("target reference read from stdin is empty")
+	}
+	return reference, nil
+}
+
+// loadUndo reads the saved undo command and returns the branch and the reference to move it to
+func loadUndo() (string, string, error) {
+	undoFile, err := getUndoFile()
+	if err != nil {
+		return "", "", err
+	}
+
+	content, err := os.ReadFile(undoFile)
+	if os.IsNotExist(err) {
+		return "", "", fmt.Errorf("no saved undo command found (use --save-undo when moving)")
+	} else if err != nil {
+		return "", "", fmt.Errorf("failed to read undo file: %v", err)
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) != 6 || fields[2] != "-b" || fields[4] != "-t" {
+		return "", "", fmt.Errorf("invalid undo file: %s", undoFile)
+	}
+	return fields[3], fields[5], nil
 }
 
 func printUsage() {
@@ -157,22 +378,35 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Optional Arguments:")
 	fmt.Println("  -t, --to <reference>  The commit/reference to move the branch to (default: HEAD)")
+	fmt.Println("                        Use - to read the reference from stdin")
 	fmt.Println()
 	fmt.Println("Options:")
-	fmt.Println("  --backup              Create a backup before moving the branch")
+	fmt.Println("  --backup              Create a backup before moving the branch (default: git-tools.auto-backup config)")
+	fmt.Println("  --no-backup           Don't create a backup, even if git-tools.auto-backup is set")
+	fmt.Println("  --tag-old <name>      Create a lightweight tag at the branch's tip before moving it")
 	fmt.Println("  --checkout            Check out the branch after moving it")
+	fmt.Println("  --no-checkout         Fail instead of switching away and back when moving the current branch")
+	fmt.Println("  --set-upstream <remote>/<branch>  Make the branch track this upstream after moving it")
+	fmt.Println("  --ff-only             Only move the branch if the new reference is a descendant of its tip")
+	fmt.Println("  --save-undo           Save the undo command to .git/git-tools/last-move-undo")
+	fmt.Println("  --undo                Move the branch back using the saved undo command")
 	fmt.Println("  -h, --help            Show this help message")
+	fmt.Println("  --version             Show the version of the tool and git")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  git-move-branch -b feature-branch                    # Move feature-branch to HEAD")
 	fmt.Println("  git-move-branch -b feature-branch -t main            # Move feature-branch to main")
 	fmt.Println("  git-move-branch --branch feature-branch --to abc123  # Move feature-branch to commit abc123")
 	fmt.Println("  git-move-branch --backup -b feature-branch -t origin/main  # Move with backup")
+	fmt.Println("  git-move-branch --tag-old before-sync -b feature-branch -t main  # Keep the old tip as a tag")
 	fmt.Println("  git-move-branch --checkout -b feature-branch -t main # Move and checkout the branch")
+	fmt.Println("  git-move-branch --save-undo -b feature-branch -t main # Move and save the undo command")
+	fmt.Println("  git-move-branch --undo                               # Undo the last saved move")
+	fmt.Println("  git-get main-branch -i | git-move-branch -b feature-branch -t -  # Move to the remote main branch")
 	fmt.Println()
 	fmt.Println("Notes:")
 	fmt.Println("  - If the branch to move is currently checked out, it will be temporarily")
-	fmt.Println("    switched to the target commit before moving, then checked out again")
+	fmt.Println("    switched to the target commit before moving, then checked out again (unless --no-checkout)")
 	fmt.Println("  - Use --backup to create a backup before moving (requires git-backup)")
 	fmt.Println("  - The new reference can be any valid git reference (branch, tag, commit hash)")
 }
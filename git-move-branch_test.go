@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"git-tools/common/testutil"
+)
+
+// runMoveBranch runs git-move-branch with a command line in the current repository
+func runMoveBranch(t *testing.T, args ...string) {
+	t.Helper()
+	previous := os.Args
+	os.Args = append([]string{"git-move-branch"}, args...)
+	t.Cleanup(func() { os.Args = previous })
+
+	main()
+}
+
+func TestMoveCurrentBranchToRelativeTarget(t *testing.T) {
+	for _, target := range []string{"main~2", "HEAD~1"} {
+		t.Run(target, func(t *testing.T) {
+			testutil.NewRepo(t)
+			testutil.Commit(t, "first")
+			testutil.Commit(t, "second")
+			testutil.Commit(t, "third")
+			// The target names this commit until main's commit is checked out
+			want := testutil.Git(t, "rev-parse", target)
+
+			runMoveBranch(t, "-b", "main", "-t", target)
+
+			if moved := testutil.Git(t, "rev-parse", "main"); moved != want {
+				t.Errorf("main is at %s, want %s which %s named before the move", moved, want, target)
+			}
+			if branch := testutil.Git(t, "branch", "--show-current"); branch != "main" {
+				t.Errorf("'%s' is checked out after the move, want main", branch)
+			}
+		})
+	}
+}
@@ -8,38 +8,74 @@ import (
 )
 
 type newBranchOptions struct {
-	name     string
-	checkout bool
-	remote   string
+	name      string
+	checkout  bool
+	remote    string
+	dryRun    bool
+	localMain bool
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		common.PrintVersion("git-new-branch")
+		os.Exit(0)
+	}
+
 	if !common.IsGitRepository() {
 		fmt.Fprintf(os.Stderr, "%sError: This directory is not a git repository.%s\n", common.ColorRed, common.ColorReset)
 		os.Exit(1)
 	}
 
-	opts, err := parseArgs()
-
+	cfg, err := common.LoadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
 
-	name, err := common.GetRemoteMainBranch(opts.remote)
+	opts, err := parseArgs(cfg)
+
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
 
-	mainBranch := fmt.Sprintf("%s/%s", opts.remote, name)
-	fmt.Printf("%sFetching '%s'%s\n", common.ColorGreen, mainBranch, common.ColorReset)
-	err = common.FetchBranch(opts.remote, name, true)
+	var name, mainBranch string
+	if opts.localMain {
+		name, err = common.LocalDefaultBranch()
+		mainBranch = name
+	} else {
+		name, err = common.GetRemoteMainBranch(opts.remote)
+		mainBranch = fmt.Sprintf("%s/%s", opts.remote, name)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%sError fetching origin branch: %v%s\n", common.ColorRed, err, common.ColorReset)
+		fmt.Fprintf(os.Stderr, "%sError: %v%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
 
+	if opts.dryRun {
+		if opts.localMain {
+			fmt.Printf("%sWould create branch '%s' from '%s'%s\n", common.ColorYellow, opts.name, mainBranch, common.ColorReset)
+		} else {
+			fmt.Printf("%sWould fetch '%s' and create branch '%s' from it%s\n", common.ColorYellow, mainBranch, opts.name, common.ColorReset)
+		}
+		if opts.checkout {
+			fmt.Printf("%sWould check out branch '%s'%s\n", common.ColorYellow, opts.name, common.ColorReset)
+		}
+		return
+	}
+
+	// The local default branch is used as is, there is nothing to fetch
+	if !opts.localMain {
+		spinner := common.NewStatusSpinner(fmt.Sprintf("Fetching '%s'", mainBranch))
+		spinner.Start()
+		err = common.FetchBranch(opts.remote, name, true)
+		spinner.Stop(err == nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError fetching origin branch: %v%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	}
+
 	fmt.Printf("%sCreating branch '%s' from '%s'\n", common.ColorGreen, opts.name, mainBranch)
 	err = common.CreateBranch(opts.name, mainBranch)
 	if err != nil {
@@ -57,10 +93,10 @@ func main() {
 	fmt.Printf("%s✅ Branch '%s' created successfully.%s\n", common.ColorGreen, opts.name, common.ColorReset)
 }
 
-func parseArgs() (*newBranchOptions, error) {
+func parseArgs(cfg *common.Config) (*newBranchOptions, error) {
 	opts := &newBranchOptions{
-		remote:   "origin",
-		checkout: true,
+		remote:   cfg.Remote,
+		checkout: !common.IsBareRepository(), // Nothing to check out in a bare repository
 	}
 	args := os.Args[1:]
 	if len(args) == 0 {
@@ -69,6 +105,7 @@ func parseArgs() (*newBranchOptions, error) {
 	}
 
 	var name string = ""
+	remoteSet := false
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
@@ -78,9 +115,14 @@ func parseArgs() (*newBranchOptions, error) {
 				return nil, fmt.Errorf("missing argument for %s", arg)
 			}
 			opts.remote = args[i+1]
+			remoteSet = true
 			i++
 		case "--no-checkout", "-n":
 			opts.checkout = false
+		case "--dry-run":
+			opts.dryRun = true
+		case "--local-main":
+			opts.localMain = true
 		default:
 			if name != "" {
 				return nil, fmt.Errorf("unknown argument: %s", arg)
@@ -94,13 +136,20 @@ func parseArgs() (*newBranchOptions, error) {
 	}
 	opts.name = name
 
+	if opts.localMain && remoteSet {
+		return nil, fmt.Errorf("--local-main and --remote cannot be combined")
+	}
+
 	return opts, nil
 }
 
 func printUsage() {
 	fmt.Println("Usage: git-new-branch [options] <branch name>")
 	fmt.Println("Options:")
-	fmt.Println("  --remote, -r      Specify the remote name (default: origin)")
+	fmt.Println("  --remote, -r      Specify the remote name (default: git-tools.remote config, or origin)")
 	fmt.Println("  --no-checkout, -n  Do not check out the new branch")
+	fmt.Println("  --dry-run         Show the base and branch that would be created, without fetching")
+	fmt.Println("  --local-main      Branch off the local default branch (init.defaultBranch, main or master) without fetching")
 	fmt.Println("  --help, -h        Show this help message")
+	fmt.Println("  --version         Show the version of the tool and git")
 }
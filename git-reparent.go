@@ -1,57 +1,123 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"git-tools/common"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type reparentOptions struct {
 	parentRef       string
 	numberOfCommits int
 	fromRef         string
+	toRef           string
 	shouldBackup    bool
 	shouldConfirm   bool
+	assumeYes       bool
 	noBranch        bool
 	continueRebase  bool
+	showStat        bool
+	resetAuthor     bool
+	autoMain        bool
+	commitsFile     string
+	squash          bool
+	squashMessage   string
+	useRebase       bool
+	resolveRules    []string
+	branch          string
+	pull            bool
+	tag             string
+	tagAnnotated    bool
+	tagMessage      string
+	keepGoing       bool
+	ontoRemoteMain  bool
+	remote          string
+	previewConflict bool
+}
+
+// tipRef returns the tip of the commits to reparent: the --branch branch, the --to ref, or HEAD
+func (opts *reparentOptions) tipRef() string {
+	if opts.branch != "" {
+		return opts.branch
+	}
+	if opts.toRef != "" {
+		return opts.toRef
+	}
+	return "HEAD"
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		common.PrintVersion("git-reparent")
+		os.Exit(0)
+	}
+
 	if !common.IsGitRepository() {
 		fmt.Fprintf(os.Stderr, "%sError: This directory is not a git repository.%s\n", common.ColorRed, common.ColorReset)
 		os.Exit(1)
 	}
 
-	if len(os.Args) > 1 && os.Args[1] == "--continue" {
-		handleContinue()
+	if common.IsBareRepository() {
+		fmt.Fprintf(os.Stderr, "%sError: git reparent requires a work tree and cannot run in a bare repository.%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 && (os.Args[1] == "--continue" || os.Args[1] == "--force-continue") {
+		err := handleContinue(os.Args[1] == "--force-continue")
+		common.LogOperation("reparent", os.Args[1:], err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
 		return
 	}
 
 	if len(os.Args) > 1 && os.Args[1] == "--abort" {
-		handleAbort()
+		err := handleAbort()
+		common.LogOperation("reparent", os.Args[1:], err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
 		return
 	}
 
-	opts, err := parseArgs()
+	cfg, err := common.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+
+	opts, err := parseArgs(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
 		printUsage()
 		os.Exit(1)
 	}
 
-	if err := runReparent(opts); err != nil {
+	tipBefore, _ := common.GetCommitHash(opts.tipRef())
+	err = runReparent(opts, cfg)
+	tipAfter, _ := common.GetCommitHash(opts.tipRef())
+	common.LogOperation("reparent", os.Args[1:], err, common.RefChange{Ref: opts.tipRef(), Before: tipBefore, After: tipAfter})
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
 }
 
-func parseArgs() (*reparentOptions, error) {
+func parseArgs(cfg *common.Config) (*reparentOptions, error) {
 	opts := &reparentOptions{
 		numberOfCommits: 1, // Default to last commit only
+		shouldBackup:    cfg.AutoBackup,
+		remote:          cfg.Remote,
 	}
+	remoteSet := false
 
 	args := os.Args[1:]
 	for i := 0; i < len(args); i++ {
@@ -79,12 +145,89 @@ func parseArgs() (*reparentOptions, error) {
 			}
 			opts.fromRef = args[i+1]
 			i++
+		case "--to":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--to requires a value")
+			}
+			opts.toRef = args[i+1]
+			i++
 		case "--backup":
 			opts.shouldBackup = true
+		case "--no-backup":
+			opts.shouldBackup = false
 		case "--confirm":
 			opts.shouldConfirm = true
+		case "--use-rebase":
+			opts.useRebase = true
+		case "--branch", "-b":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--branch requires a value")
+			}
+			opts.branch = args[i+1]
+			i++
+		case "--resolve":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--resolve requires a value")
+			}
+			if _, _, err := parseResolveRule(args[i+1]); err != nil {
+				return nil, err
+			}
+			opts.resolveRules = append(opts.resolveRules, args[i+1])
+			i++
+		case "--yes", "-y":
+			opts.assumeYes = true
+		case "--pull":
+			opts.pull = true
+		case "--keep-going":
+			opts.keepGoing = true
+		case "--preview-conflicts":
+			opts.previewConflict = true
+		case "--onto-remote-main":
+			opts.ontoRemoteMain = true
+		case "--remote":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--remote requires a value")
+			}
+			opts.remote = args[i+1]
+			remoteSet = true
+			i++
+		case "--tag":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--tag requires a value")
+			}
+			opts.tag = args[i+1]
+			i++
+		case "--annotated":
+			opts.tagAnnotated = true
+		case "--tag-message":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--tag-message requires a value")
+			}
+			opts.tagMessage = args[i+1]
+			opts.tagAnnotated = true
+			i++
 		case "--no-branch":
 			opts.noBranch = true
+		case "--stat":
+			opts.showStat = true
+		case "--reset-author":
+			opts.resetAuthor = true
+		case "--auto-main":
+			opts.autoMain = true
+		case "--squash":
+			opts.squash = true
+		case "--message", "-m":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--message requires a value")
+			}
+			opts.squashMessage = args[i+1]
+			i++
+		case "--commits-file":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--commits-file requires a value")
+			}
+			opts.commitsFile = args[i+1]
+			i++
 		case "--help", "-h":
 			printUsage()
 			os.Exit(0)
@@ -93,10 +236,55 @@ func parseArgs() (*reparentOptions, error) {
 		}
 	}
 
-	if opts.parentRef == "" {
+	if opts.ontoRemoteMain && opts.parentRef != "" {
+		return nil, fmt.Errorf("--onto-remote-main and --parent cannot be combined")
+	}
+	if remoteSet && !opts.ontoRemoteMain {
+		return nil, fmt.Errorf("--remote can only be used with --onto-remote-main")
+	}
+	if opts.parentRef == "" && !opts.ontoRemoteMain {
 		return nil, fmt.Errorf("--parent is required")
 	}
 
+	if opts.useRebase && (opts.commitsFile != "" || opts.noBranch || opts.resetAuthor || opts.showStat || len(opts.resolveRules) > 0 || opts.keepGoing) {
+		return nil, fmt.Errorf("--use-rebase cannot be combined with --commits-file, --no-branch, --reset-author, --stat, --resolve or --keep-going")
+	}
+
+	if opts.previewConflict && (opts.shouldConfirm || opts.tag != "" || opts.keepGoing || len(opts.resolveRules) > 0) {
+		return nil, fmt.Errorf("--preview-conflicts only reports conflicts and cannot be combined with --confirm, --tag, --keep-going or --resolve")
+	}
+
+	if opts.branch != "" && !common.IsBranch(opts.branch) {
+		return nil, fmt.Errorf("branch '%s' does not exist", opts.branch)
+	}
+
+	if opts.toRef != "" {
+		if err := resolveToRef(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.assumeYes && !opts.shouldConfirm {
+		return nil, fmt.Errorf("--yes can only be used with --confirm")
+	}
+
+	if opts.squashMessage != "" && !opts.squash {
+		return nil, fmt.Errorf("--message can only be used with --squash")
+	}
+
+	// Check the tag now rather than after all commits were moved
+	if opts.tagAnnotated && opts.tag == "" {
+		return nil, fmt.Errorf("--annotated and --tag-message can only be used with --tag")
+	}
+	if opts.tag != "" {
+		if !common.IsValidRefName("refs/tags/" + opts.tag) {
+			return nil, fmt.Errorf("'%s' is not a valid tag name", opts.tag)
+		}
+		if common.IsTag(opts.tag) {
+			return nil, fmt.Errorf("tag '%s' already exists", opts.tag)
+		}
+	}
+
 	// Validate that both --number and --from are not specified
 	if opts.fromRef != "" && opts.numberOfCommits != 1 {
 		return nil, fmt.Errorf("cannot specify both --number and --from")
@@ -105,20 +293,85 @@ func parseArgs() (*reparentOptions, error) {
 	return opts, nil
 }
 
-func runReparent(opts *reparentOptions) error {
+// resolveToRef picks the branch moved when the range ends at --to: the ref itself if it's a
+// branch, else the one branch pointing at it. Without any, the result is left detached.
+func resolveToRef(opts *reparentOptions) error {
+	if opts.branch != "" {
+		return fmt.Errorf("--to and --branch cannot be combined, --to moves the branch pointing at its ref")
+	}
+	if opts.commitsFile != "" {
+		return fmt.Errorf("--to cannot be combined with --commits-file")
+	}
+	if !common.GitRefExists(opts.toRef) {
+		return fmt.Errorf("to reference '%s' does not exist", opts.toRef)
+	}
+
+	if common.IsBranch(opts.toRef) {
+		opts.branch = opts.toRef
+		return nil
+	}
+
+	branches, err := common.GetBranchesPointingAt(opts.toRef)
+	if err != nil {
+		return fmt.Errorf("failed to get the branches pointing at '%s': %v", opts.toRef, err)
+	}
+	switch len(branches) {
+	case 0:
+		if opts.useRebase {
+			return fmt.Errorf("no branch points at '%s', which --use-rebase needs to move", opts.toRef)
+		}
+		opts.noBranch = true
+	case 1:
+		opts.branch = branches[0]
+	default:
+		return fmt.Errorf("several branches point at '%s' (%s), pass the one to move to --to", opts.toRef, strings.Join(branches, ", "))
+	}
+	return nil
+}
+
+func runReparent(opts *reparentOptions, cfg *common.Config) error {
 	fmt.Printf("%s🔄 Git Reparent Process Starting...%s\n", common.ColorCyan, common.ColorReset)
 
-	if common.HasUncommittedChanges() {
+	// The preview runs in its own worktree, local changes don't get in its way
+	if !opts.previewConflict && common.HasUncommittedChanges() {
 		return fmt.Errorf("there are uncommitted changes. Please commit or stash them first")
 	}
 
-	if !common.GitRefExists(opts.parentRef) {
-		return fmt.Errorf("parent reference '%s' does not exist", opts.parentRef)
+	// Don't wait for an answer that can never come
+	if opts.shouldConfirm && !opts.assumeYes && !common.IsTerminal(os.Stdin) {
+		return fmt.Errorf("--confirm needs an interactive terminal. Use --yes to proceed without prompting")
+	}
+
+	if opts.ontoRemoteMain {
+		if err := fetchRemoteMainParent(opts); err != nil {
+			return err
+		}
+	}
+
+	if err := resolveParentRef(opts, cfg); err != nil {
+		return err
+	}
+
+	// The remote main branch was just fetched, there is nothing left to pull
+	if opts.pull && !opts.ontoRemoteMain {
+		if err := pullParent(opts.parentRef); err != nil {
+			return err
+		}
 	}
 
-	if opts.shouldBackup {
+	if opts.toRef != "" && opts.branch == "" {
+		fmt.Printf("%sWarning: No branch points at '%s', the reparented commits will be left detached%s\n", common.ColorYellow, opts.toRef, common.ColorReset)
+	}
+
+	if opts.shouldBackup && !opts.previewConflict {
 		fmt.Printf("%s▶️ Creating backup...%s\n", common.ColorYellow, common.ColorReset)
-		if err := common.RunGitBackup(); err != nil {
+		var err error
+		if opts.branch != "" {
+			err = common.RunGitBackupWithRef(opts.branch, "reparent")
+		} else {
+			err = common.RunGitBackup("reparent")
+		}
+		if err != nil {
 			return fmt.Errorf("failed to create backup: %v", err)
 		}
 		fmt.Printf("%s✅ Backup created successfully%s\n", common.ColorGreen, common.ColorReset)
@@ -130,8 +383,16 @@ func runReparent(opts *reparentOptions) error {
 		return fmt.Errorf("failed to get parent commit hash: %v", err)
 	}
 
-	currentBranch, err := common.GetCurrentBranch()
-	if err != nil {
+	// With --branch, the reparented branch may not be the one checked out, which is returned to at the end
+	var currentBranch, returnTo string
+	if opts.branch != "" {
+		currentBranch = opts.branch
+		if returnTo, err = common.GetCurrentBranch(); err != nil {
+			if returnTo, err = common.GetCommitHash("HEAD"); err != nil {
+				return fmt.Errorf("failed to get HEAD: %v", err)
+			}
+		}
+	} else if currentBranch, err = common.GetCurrentBranch(); err != nil {
 		return fmt.Errorf("failed to get current branch: %v", err)
 	}
 	commits, err := getCommitsToReparent(opts)
@@ -143,92 +404,494 @@ func runReparent(opts *reparentOptions) error {
 		return fmt.Errorf("no commits to reparent")
 	}
 
+	if opts.previewConflict {
+		return previewConflicts(parentCommit, commits)
+	}
+
 	if opts.shouldConfirm {
 		fmt.Printf("\n%sReparent Summary:%s\n", common.ColorCyan, common.ColorReset)
 		fmt.Printf("%s  Current branch:  %s%s\n", common.ColorWhite, currentBranch, common.ColorReset)
 		fmt.Printf("%s  New parent:      %s (%s)%s\n", common.ColorWhite, opts.parentRef, parentCommit[:8], common.ColorReset)
 		fmt.Printf("%s  Commits to move: %d%s\n", common.ColorWhite, len(commits), common.ColorReset)
+		subjects, _ := common.GetCommitSubjects(commits)
 		for i, commit := range commits {
-			commitMsg, _ := common.GetCommitMessage(commit)
+			commitMsg := subjects[commit]
 			fmt.Printf("%s    %d. %s - %s%s\n", common.ColorWhite, i+1, commit[:8], commitMsg, common.ColorReset)
 		}
 		if !opts.noBranch {
 			fmt.Printf("%s  Branch will be moved to new location%s\n", common.ColorWhite, common.ColorReset)
 		}
 
-		fmt.Printf("\n%sProceed with reparent? (y/N): %s", common.ColorYellow, common.ColorReset)
-		var response string
-		fmt.Scanln(&response)
-		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
-			fmt.Printf("%sReparent cancelled%s\n", common.ColorYellow, common.ColorReset)
-			return nil
+		if opts.assumeYes {
+			fmt.Printf("\n%sProceeding with reparent (--yes)%s\n", common.ColorYellow, common.ColorReset)
+		} else {
+			fmt.Printf("\n%sProceed with reparent? (y/N): %s", common.ColorYellow, common.ColorReset)
+			var response string
+			fmt.Scanln(&response)
+			if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+				fmt.Printf("%sReparent cancelled%s\n", common.ColorYellow, common.ColorReset)
+				return nil
+			}
 		}
 	}
 
+	if opts.useRebase {
+		return runRebaseReparent(opts, currentBranch, returnTo, parentCommit, commits)
+	}
+
 	fmt.Printf("%s▶️ Checking out new parent as detached HEAD...%s\n", common.ColorYellow, common.ColorReset)
 	if err := common.Checkout(parentCommit); err != nil {
 		return fmt.Errorf("failed to checkout parent commit: %v", err)
 	}
 
-	if err := saveReparentState(commits, currentBranch, opts.noBranch); err != nil {
+	state := &reparentState{
+		remainingCommits: commits,
+		originalBranch:   currentBranch,
+		returnTo:         returnTo,
+		noBranch:         opts.noBranch,
+		showStat:         opts.showStat,
+		resetAuthor:      opts.resetAuthor,
+		totalCommits:     len(commits),
+		startTime:        time.Now(),
+		squash:           opts.squash,
+		squashMessage:    opts.squashMessage,
+		parentCommit:     parentCommit,
+		resolveRules:     opts.resolveRules,
+		tag:              opts.tag,
+		tagAnnotated:     opts.tagAnnotated,
+		tagMessage:       opts.tagMessage,
+		keepGoing:        opts.keepGoing,
+	}
+	if state.squash && state.squashMessage == "" {
+		state.squashMessage = defaultSquashMessage(commits)
+	}
+	if err := saveReparentState(state); err != nil {
+		return fmt.Errorf("failed to save reparent state: %v", err)
+	}
+
+	if err := applyCherryPicks(state); err != nil {
+		return err
+	}
+
+	return finishReparent(state)
+}
+
+// previewConflicts cherry-picks the commits onto the parent in a temporary worktree and reports
+// which ones conflict. Neither the branch nor the current worktree are touched.
+func previewConflicts(parentCommit string, commits []string) error {
+	previewDir, err := os.MkdirTemp("", "git-reparent-preview-")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(previewDir)
+
+	fmt.Printf("%s▶️ Trying the commits on %s in a temporary worktree...%s\n", common.ColorYellow, parentCommit[:8], common.ColorReset)
+	if err := common.AddDetachedWorktree(previewDir, parentCommit); err != nil {
+		return fmt.Errorf("failed to create the temporary worktree: %v", err)
+	}
+	defer func() {
+		if err := common.RemoveWorktree(previewDir); err != nil {
+			fmt.Printf("%sWarning: Failed to remove the temporary worktree %s: %v%s\n", common.ColorYellow, previewDir, err, common.ColorReset)
+		}
+	}()
+
+	subjects, _ := common.GetCommitSubjects(commits)
+	conflicting := 0
+	for i, commit := range commits {
+		conflicts, empty, err := common.TrialCherryPick(previewDir, commit)
+		if err != nil {
+			return fmt.Errorf("failed to try commit %s: %v", commit[:8], err)
+		}
+
+		label := fmt.Sprintf("%d/%d %s - %s", i+1, len(commits), commit[:8], subjects[commit])
+		switch {
+		case len(conflicts) > 0:
+			conflicting++
+			fmt.Printf("%s⚠️ %s%s\n", common.ColorRed, label, common.ColorReset)
+			for _, file := range conflicts {
+				fmt.Printf("%s      conflict: %s%s\n", common.ColorRed, file, common.ColorReset)
+			}
+		case empty:
+			fmt.Printf("%s⏭️ %s (already in the parent, would be empty)%s\n", common.ColorYellow, label, common.ColorReset)
+		default:
+			fmt.Printf("%s✅ %s%s\n", common.ColorGreen, label, common.ColorReset)
+		}
+	}
+
+	if conflicting > 0 {
+		// Later commits were tried on top of the conflicting ones' own version of the files
+		return fmt.Errorf("%d of %d commits would conflict", conflicting, len(commits))
+	}
+	fmt.Printf("%s✅ All %d commits apply cleanly%s\n", common.ColorGreen, len(commits), common.ColorReset)
+	return nil
+}
+
+// runRebaseReparent moves the commits with git rebase --onto instead of cherry-picking them
+// one by one, which keeps merge commits. Conflicts are handled by git's own rebase state.
+func runRebaseReparent(opts *reparentOptions, currentBranch, returnTo, parentCommit string, commits []string) error {
+	baseRef := opts.fromRef
+	if baseRef == "" {
+		baseRef = fmt.Sprintf("%s~%d", opts.tipRef(), opts.numberOfCommits)
+	}
+	baseCommit, err := common.GetCommitHash(baseRef)
+	if err != nil {
+		return fmt.Errorf("failed to get base commit hash: %v", err)
+	}
+
+	state := &reparentState{
+		originalBranch: currentBranch,
+		returnTo:       returnTo,
+		totalCommits:   len(commits),
+		startTime:      time.Now(),
+		squash:         opts.squash,
+		squashMessage:  opts.squashMessage,
+		parentCommit:   parentCommit,
+		useRebase:      true,
+		tag:            opts.tag,
+		tagAnnotated:   opts.tagAnnotated,
+		tagMessage:     opts.tagMessage,
+	}
+	if state.squash && state.squashMessage == "" {
+		state.squashMessage = defaultSquashMessage(commits)
+	}
+	if err := saveReparentState(state); err != nil {
 		return fmt.Errorf("failed to save reparent state: %v", err)
 	}
 
-	if err := applyCherryPicks(commits); err != nil {
+	fmt.Printf("%s▶️ Rebasing %d commit(s) onto %s...%s\n", common.ColorYellow, len(commits), parentCommit[:8], common.ColorReset)
+	if err := common.RebaseOnto(parentCommit, baseCommit, currentBranch); err != nil {
+		return handleRebaseStop(state, err)
+	}
+	fmt.Printf("%s✅ Rebase successful%s\n", common.ColorGreen, common.ColorReset)
+
+	return finishReparent(state)
+}
+
+// handleRebaseStop records a conflict that stopped the rebase, or reports the failure
+func handleRebaseStop(state *reparentState, err error) error {
+	if !common.IsRebaseInProgress() {
+		return fmt.Errorf("rebase failed: %v", err)
+	}
+
+	fmt.Printf("%s⚠️ Rebase resulted in conflicts%s\n", common.ColorYellow, common.ColorReset)
+	fmt.Printf("%sResolve the conflicts and run:%s\n", common.ColorWhite, common.ColorReset)
+	fmt.Printf("%s  git add <resolved-files>%s\n", common.ColorWhite, common.ColorReset)
+	fmt.Printf("%s  git reparent --continue%s\n", common.ColorWhite, common.ColorReset)
+
+	state.conflicts++
+	if err := saveReparentState(state); err != nil {
+		return fmt.Errorf("failed to update reparent state: %v", err)
+	}
+	return fmt.Errorf("rebase conflicts require manual resolution")
+}
+
+// continueRebaseReparent continues the rebase started with --use-rebase, then finishes the reparent
+func continueRebaseReparent(state *reparentState) error {
+	if common.IsRebaseInProgress() {
+		if common.HasConflicts() {
+			fmt.Fprintf(os.Stderr, "%sResolve them, stage the files with 'git add <resolved-files>', then run 'git reparent --continue' again%s\n", common.ColorYellow, common.ColorReset)
+			return fmt.Errorf("there are still unresolved conflicts")
+		}
+
+		fmt.Printf("%s▶️ Rebase is in progress, attempting to continue...%s\n", common.ColorYellow, common.ColorReset)
+		if err := common.ContinueRebase(); err != nil {
+			return handleRebaseStop(state, err)
+		}
+		fmt.Printf("%s✅ Rebase continued successfully%s\n", common.ColorGreen, common.ColorReset)
+	}
+
+	return finishReparent(state)
+}
+
+// resolveParentRef validates the parent reference. With --auto-main, a missing <remote>/<name>
+// parent falls back to the main branch of that remote (e.g. origin/master instead of origin/main).
+func resolveParentRef(opts *reparentOptions, cfg *common.Config) error {
+	// @<bookmark> stands for what the bookmark points to, e.g. @base or @base~1
+	if name, found := strings.CutPrefix(opts.parentRef, "@"); found && name != "" && !strings.HasPrefix(name, "{") {
+		bookmarksDir, err := common.GetBookmarksDirectory(cfg)
+		if err != nil {
+			return err
+		}
+		reference, err := common.ResolveBookmarkExpression(bookmarksDir, name)
+		if err != nil {
+			return fmt.Errorf("cannot use '%s' as parent: %v", opts.parentRef, err)
+		}
+		fmt.Printf("%sParent bookmark '%s' points to '%s'%s\n", common.ColorGreen, name, reference, common.ColorReset)
+		opts.parentRef = reference
+	}
+
+	// '.', @{u} and @{upstream} stand for the upstream of the current branch
+	switch opts.parentRef {
+	case ".", "@{u}", "@{upstream}":
+		upstream, err := common.GetUpstream("")
+		if err != nil {
+			return fmt.Errorf("cannot use '%s' as parent: the current branch has no upstream", opts.parentRef)
+		}
+		opts.parentRef = upstream
+	}
+
+	if common.GitRefExists(opts.parentRef) {
+		// Refuse to guess between a branch and a tag with the same name
+		if _, err := common.ResolveUnambiguousRef(opts.parentRef); err != nil {
+			return fmt.Errorf("parent %v", err)
+		}
+		return nil
+	}
+
+	remote, _, found := strings.Cut(opts.parentRef, "/")
+	if !opts.autoMain || !found {
+		return parentNotFoundError(opts.parentRef)
+	}
+
+	mainBranch, err := common.GetRemoteMainBranch(remote)
+	if err != nil {
+		return fmt.Errorf("parent reference '%s' does not exist, and the main branch of '%s' could not be determined: %v", opts.parentRef, remote, err)
+	}
+
+	substitute := remote + "/" + mainBranch
+	if !common.GitRefExists(substitute) {
+		return fmt.Errorf("parent reference '%s' does not exist, and neither does '%s'", opts.parentRef, substitute)
+	}
+
+	fmt.Printf("%s⚠️ Parent '%s' does not exist, using main branch '%s' instead%s\n", common.ColorYellow, opts.parentRef, substitute, common.ColorReset)
+	opts.parentRef = substitute
+	return nil
+}
+
+// pullParent brings the parent up to date with its remote. A remote branch is fetched; a local branch
+// tracking a remote is fetched then fast-forwarded, and is never force-updated.
+func pullParent(parentRef string) error {
+	switch common.ClassifyRef(parentRef) {
+	case common.RefRemoteBranch:
+		remote, branch, _ := strings.Cut(parentRef, "/")
+		return fetchParent(remote, branch)
+	case common.RefBranch:
+		// Handled below
+	default:
+		return fmt.Errorf("--pull needs the parent to be a branch or a remote branch, '%s' is neither", parentRef)
+	}
+
+	branch := parentRef
+	if !common.IsBranch(branch) {
+		branch = common.GetBranchName(parentRef)
+	}
+	upstream, err := common.GetUpstream(branch)
+	if err != nil {
+		return fmt.Errorf("--pull needs parent branch '%s' to track a remote branch", branch)
+	}
+	remote, err := common.GetBranchRemote(branch)
+	if err != nil {
+		return err
+	}
+	if remote != "." {
+		if err := fetchParent(remote, ""); err != nil {
+			return err
+		}
+	}
+
+	ahead, behind, err := common.AheadBehind(upstream, branch)
+	if err != nil {
+		return fmt.Errorf("failed to compare '%s' with '%s': %v", branch, upstream, err)
+	}
+	if ahead > 0 && behind > 0 {
+		return fmt.Errorf("parent branch '%s' has diverged from '%s' (%d ahead, %d behind) and can't be fast-forwarded", branch, upstream, ahead, behind)
+	}
+	if behind == 0 {
+		fmt.Printf("%s✅ Parent '%s' is up to date with '%s'%s\n", common.ColorGreen, branch, upstream, common.ColorReset)
+		return nil
+	}
+
+	fmt.Printf("%s▶️ Fast-forwarding '%s' to '%s' (%d commit(s))...%s\n", common.ColorYellow, branch, upstream, behind, common.ColorReset)
+	if err := common.FastForwardBranch(branch, upstream); err != nil {
+		return fmt.Errorf("failed to fast-forward '%s': %v", branch, err)
+	}
+	fmt.Printf("%s✅ Parent '%s' fast-forwarded%s\n", common.ColorGreen, branch, common.ColorReset)
+	return nil
+}
+
+// fetchRemoteMainParent fetches the main branch of the remote and makes it the parent
+func fetchRemoteMainParent(opts *reparentOptions) error {
+	mainBranch, err := common.GetRemoteMainBranch(opts.remote)
+	if err != nil {
+		return fmt.Errorf("could not determine the main branch of '%s', run 'git remote set-head %s --auto' to detect it: %v", opts.remote, opts.remote, err)
+	}
+
+	if err := fetchParent(opts.remote, mainBranch); err != nil {
 		return err
 	}
 
-	return finishReparent(currentBranch, opts.noBranch)
+	opts.parentRef = opts.remote + "/" + mainBranch
+	fmt.Printf("%sParent: %s%s\n", common.ColorGreen, opts.parentRef, common.ColorReset)
+	return nil
 }
 
-func handleContinue() {
+// fetchParent fetches a branch from a remote, or the whole remote if branch is empty
+func fetchParent(remote, branch string) error {
+	var err error
+	if branch != "" {
+		spinner := common.NewStatusSpinner(fmt.Sprintf("Fetching '%s/%s'", remote, branch))
+		spinner.Start()
+		err = common.FetchBranch(remote, branch, false)
+		spinner.Stop(err == nil)
+	} else {
+		spinner := common.NewStatusSpinner(fmt.Sprintf("Fetching '%s'", remote))
+		spinner.Start()
+		err = common.FetchRemote(remote)
+		spinner.Stop(err == nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch the parent from '%s': %v", remote, err)
+	}
+	return nil
+}
+
+// commitHashPattern matches full and abbreviated commit hashes
+var commitHashPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+// parentNotFoundError tells apart a hash missing from the repository from a ref name that doesn't exist
+func parentNotFoundError(parentRef string) error {
+	if commitHashPattern.MatchString(parentRef) {
+		return fmt.Errorf("parent '%s' looks like a commit hash, but no such commit is in this repository. If it comes from a remote, run 'git fetch' first", parentRef)
+	}
+	return fmt.Errorf("parent reference '%s' does not exist", parentRef)
+}
+
+func handleContinue(force bool) error {
 	fmt.Printf("%s🔄 Continuing git reparent...%s\n", common.ColorCyan, common.ColorReset)
 
 	if !isReparentInProgress() {
-		fmt.Fprintf(os.Stderr, "%sError: No reparent in progress%s\n", common.ColorRed, common.ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("no reparent in progress")
 	}
 
 	state, err := loadReparentState()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
-		fmt.Fprintf(os.Stderr, "%sUse 'git reparent --abort' to cancel the reparent operation%s\n", common.ColorYellow, common.ColorReset)
-		os.Exit(1)
+		if !reparentStateFileExists() {
+			fmt.Fprintf(os.Stderr, "%sThe remaining commits are unknown. Cherry-pick them manually with 'git cherry-pick <commit>...',%s\n", common.ColorYellow, common.ColorReset)
+			fmt.Fprintf(os.Stderr, "%sthen move your branch with 'git move-branch -b <branch>' and run 'git reparent --abort' to clear the reparent markers%s\n", common.ColorYellow, common.ColorReset)
+		} else {
+			fmt.Fprintf(os.Stderr, "%sUse 'git reparent --abort' to cancel the reparent operation%s\n", common.ColorYellow, common.ColorReset)
+		}
+		return err
 	}
 
+	if state.useRebase {
+		return continueRebaseReparent(state)
+	}
+
+	// REPARENT_HEAD may have been deleted while the state survived, recreate it from HEAD
+	if !reparentHeadExists() {
+		fmt.Printf("%sWarning: REPARENT_HEAD is missing, recreating it from HEAD%s\n", common.ColorYellow, common.ColorReset)
+		if err := createReparentHead(); err != nil {
+			fmt.Printf("%sWarning: Failed to recreate REPARENT_HEAD: %v%s\n", common.ColorYellow, err, common.ColorReset)
+		}
+	}
+
+	// The cherry-pick may have been skipped, aborted or replaced by hand, continuing would then
+	// silently drop or duplicate commits
+	mismatches := checkCherryPickState(state)
+	if len(mismatches) > 0 {
+		for _, mismatch := range mismatches {
+			fmt.Fprintf(os.Stderr, "%sWarning: %s%s\n", common.ColorYellow, mismatch, common.ColorReset)
+		}
+		if !force {
+			fmt.Fprintf(os.Stderr, "%sUse 'git reparent --force-continue' to continue with the reparent's remaining commits anyway, or 'git reparent --abort' to cancel%s\n", common.ColorYellow, common.ColorReset)
+			return fmt.Errorf("git's cherry-pick state doesn't match the reparent")
+		}
+		fmt.Printf("%s▶️ Continuing with the reparent's %d remaining commit(s)...%s\n", common.ColorYellow, len(state.remainingCommits), common.ColorReset)
+	}
+
+	// The cherry-pick may already have been continued manually, in which case CHERRY_PICK_HEAD is gone
+	sequencerTodo, _ := common.GetSequencerTodo()
 	if common.IsCherryPickInProgress() {
+		if common.HasConflicts() && len(state.resolveRules) > 0 {
+			autoResolveConflicts(state.resolveRules)
+		}
+		if common.HasConflicts() {
+			fmt.Fprintf(os.Stderr, "%sResolve them, stage the files with 'git add <resolved-files>', then run 'git reparent --continue' again%s\n", common.ColorYellow, common.ColorReset)
+			return fmt.Errorf("there are still unresolved conflicts")
+		}
+
 		fmt.Printf("%s▶️ Cherry-pick is in progress, attempting to continue...%s\n", common.ColorYellow, common.ColorReset)
 		if err := common.ContinueCherryPick(); err != nil {
-			fmt.Fprintf(os.Stderr, "%sError: Failed to continue cherry-pick: %s%s\n", common.ColorRed, err, common.ColorReset)
 			fmt.Fprintf(os.Stderr, "%sPlease resolve any remaining conflicts and run 'git cherry-pick --continue' manually%s\n", common.ColorYellow, common.ColorReset)
-			os.Exit(1)
+			return fmt.Errorf("failed to continue cherry-pick: %v", err)
 		}
 		fmt.Printf("%s✅ Cherry-pick continued successfully%s\n", common.ColorGreen, common.ColorReset)
+
+		// A multi-commit cherry-pick matching the reparent picked the remaining commits itself
+		if len(mismatches) == 0 && len(sequencerTodo) > 0 {
+			state.remainingCommits = nil
+		}
+	} else {
+		fmt.Printf("%s▶️ No cherry-pick in progress, resuming with the remaining commits...%s\n", common.ColorYellow, common.ColorReset)
 	}
 
-	if err := applyCherryPicks(state.remainingCommits); err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
-		os.Exit(1)
+	// The conflicted commit was committed by git, so reattribute it if requested
+	if state.resetAuthor {
+		if err := resetAuthorOfResolvedCommit(); err != nil {
+			return err
+		}
 	}
 
-	if err := finishReparent(state.originalBranch, state.noBranch); err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
-		os.Exit(1)
+	if err := applyCherryPicks(state); err != nil {
+		return err
 	}
+
+	return finishReparent(state)
 }
 
-func handleAbort() {
+// checkCherryPickState compares the commits the reparent expects to pick with what git is
+// actually doing, and describes each difference
+func checkCherryPickState(state *reparentState) []string {
+	var mismatches []string
+
+	if state.currentCommit != "" {
+		if pickHead := common.GetCherryPickHead(); pickHead != "" && pickHead != state.currentCommit {
+			mismatches = append(mismatches, fmt.Sprintf("git is cherry-picking %s, but the reparent stopped on %s", pickHead[:8], state.currentCommit[:8]))
+		} else if pickHead == "" {
+			// Without a cherry-pick in progress, HEAD only moves if the conflicting commit was committed
+			reparentHead, err := readReparentHead()
+			headCommit, headErr := common.GetCommitHash("HEAD")
+			if err == nil && headErr == nil && reparentHead == headCommit {
+				mismatches = append(mismatches, fmt.Sprintf("commit %s was not committed, the cherry-pick was probably skipped or aborted", state.currentCommit[:8]))
+			}
+		}
+	}
+
+	todo, err := common.GetSequencerTodo()
+	if err != nil {
+		mismatches = append(mismatches, fmt.Sprintf("failed to read git's cherry-pick sequence: %v", err))
+	} else if len(todo) > 0 {
+		expected := state.remainingCommits
+		if state.currentCommit != "" {
+			expected = append([]string{state.currentCommit}, expected...)
+		}
+		if strings.Join(todo, " ") != strings.Join(expected, " ") {
+			mismatches = append(mismatches, fmt.Sprintf("git's cherry-pick sequence has %d commit(s) to pick, the reparent expects %d", len(todo), len(expected)))
+		}
+	}
+
+	return mismatches
+}
+
+func handleAbort() error {
 	fmt.Printf("%s🔄 Aborting git reparent...%s\n", common.ColorCyan, common.ColorReset)
 
 	if !isReparentInProgress() {
-		fmt.Fprintf(os.Stderr, "%sError: No reparent in progress%s\n", common.ColorRed, common.ColorReset)
-		os.Exit(1)
+		return fmt.Errorf("no reparent in progress")
 	}
 
 	state, err := loadReparentState()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
-		os.Exit(1)
+	if err != nil && reparentStateFileExists() {
+		return err
+	}
+
+	// A rebase restores the original branch when aborted
+	if state != nil && state.useRebase && common.IsRebaseInProgress() {
+		fmt.Printf("%s▶️ Aborting rebase in progress...%s\n", common.ColorYellow, common.ColorReset)
+		if err := common.AbortRebase(); err != nil {
+			fmt.Printf("%sWarning: Failed to abort rebase: %v%s\n", common.ColorYellow, err, common.ColorReset)
+		}
 	}
 
 	// If there's a cherry-pick in progress, abort it first
@@ -239,10 +902,31 @@ func handleAbort() {
 		}
 	}
 
-	fmt.Printf("%s▶️ Checking out original branch '%s'...%s\n", common.ColorYellow, state.originalBranch, common.ColorReset)
-	if err := common.Checkout(state.originalBranch); err != nil {
-		fmt.Fprintf(os.Stderr, "%sError: Failed to checkout original branch: %v%s\n", common.ColorRed, err, common.ColorReset)
-		os.Exit(1)
+	// Without the state file the original branch is unknown, only clear the markers
+	if state == nil {
+		if err := cleanupReparentState(); err != nil {
+			fmt.Printf("%sWarning: Failed to cleanup reparent state: %v%s\n", common.ColorYellow, err, common.ColorReset)
+		}
+		fmt.Printf("%sWarning: The reparent state file was missing, so the original branch is unknown.%s\n", common.ColorYellow, common.ColorReset)
+		fmt.Printf("%sReparent markers were cleared, check out your branch manually.%s\n", common.ColorYellow, common.ColorReset)
+		return nil
+	}
+
+	// Leftovers of a conflict resolution would make the checkout fail and leave the abort half done
+	if common.HasUncommittedChanges() {
+		fmt.Printf("%s⚠️ Discarding uncommitted changes, including any conflict resolution in progress...%s\n", common.ColorYellow, common.ColorReset)
+		if err := common.HardReset("HEAD"); err != nil {
+			fmt.Printf("%sWarning: Failed to discard changes: %v%s\n", common.ColorYellow, err, common.ColorReset)
+		}
+	}
+
+	original := state.originalBranch
+	if state.returnTo != "" {
+		original = state.returnTo
+	}
+	fmt.Printf("%s▶️ Checking out original branch '%s'...%s\n", common.ColorYellow, original, common.ColorReset)
+	if err := common.Checkout(original); err != nil {
+		return fmt.Errorf("failed to checkout original branch: %v", err)
 	}
 
 	if err := cleanupReparentState(); err != nil {
@@ -250,34 +934,238 @@ func handleAbort() {
 	}
 
 	fmt.Printf("%s✅ Reparent aborted successfully%s\n", common.ColorGreen, common.ColorReset)
+	return nil
 }
 
-func applyCherryPicks(commits []string) error {
+func applyCherryPicks(state *reparentState) error {
+	commits := state.remainingCommits
+
+	// When resuming, number the commits from where the reparent stopped rather than from 1
+	total := state.totalCommits
+	applied := total - len(commits)
+	if applied < 0 {
+		total, applied = len(commits), 0
+	}
+	resuming := ""
+	if applied > 0 {
+		resuming = " (continuing)"
+	}
+
 	for i, commit := range commits {
-		fmt.Printf("%s▶️ Cherry-picking commit %d/%d: %s%s\n", common.ColorYellow, i+1, len(commits), commit[:8], common.ColorReset)
+		fmt.Printf("%s▶️ Cherry-picking commit %d/%d%s: %s%s\n", common.ColorYellow, applied+i+1, total, resuming, commit[:8], common.ColorReset)
 
 		if err := common.CherryPickCommit(commit); err != nil {
-			if common.HasConflicts() {
-				fmt.Printf("%s⚠️ Cherry-pick resulted in conflicts%s\n", common.ColorYellow, common.ColorReset)
-				fmt.Printf("%sResolve the conflicts and run:%s\n", common.ColorWhite, common.ColorReset)
-				fmt.Printf("%s  git add <resolved-files>%s\n", common.ColorWhite, common.ColorReset)
-				fmt.Printf("%s  git cherry-pick --continue%s\n", common.ColorWhite, common.ColorReset)
-				fmt.Printf("%s  git reparent --continue%s\n", common.ColorWhite, common.ColorReset)
-
-				remainingCommits := commits[i+1:]
-				if err := updateReparentState(remainingCommits); err != nil {
-					return fmt.Errorf("failed to update reparent state: %v", err)
+			if common.HasConflicts() && autoResolveConflicts(state.resolveRules) {
+				if err := common.ContinueCherryPickWithoutEditing(); err != nil {
+					return fmt.Errorf("failed to continue cherry-pick after resolving conflicts: %v", err)
 				}
-				return fmt.Errorf("cherry-pick conflicts require manual resolution")
+				fmt.Printf("%s✅ Conflicts resolved with the --resolve rules%s\n", common.ColorGreen, common.ColorReset)
+				state.conflicts++
+			} else if common.HasConflicts() {
+				if !state.keepGoing || !waitForConflictResolution(state) {
+					fmt.Printf("%s⚠️ Cherry-pick resulted in conflicts%s\n", common.ColorYellow, common.ColorReset)
+					fmt.Printf("%sResolve the conflicts and run:%s\n", common.ColorWhite, common.ColorReset)
+					fmt.Printf("%s  git add <resolved-files>%s\n", common.ColorWhite, common.ColorReset)
+					fmt.Printf("%s  git cherry-pick --continue%s\n", common.ColorWhite, common.ColorReset)
+					fmt.Printf("%s  git reparent --continue%s\n", common.ColorWhite, common.ColorReset)
+
+					state.currentCommit = commit
+					state.remainingCommits = commits[i+1:]
+					state.conflicts++
+					if err := saveReparentState(state); err != nil {
+						return fmt.Errorf("failed to update reparent state: %v", err)
+					}
+					return fmt.Errorf("cherry-pick conflicts require manual resolution")
+				}
+				if err := common.ContinueCherryPickWithoutEditing(); err != nil {
+					return fmt.Errorf("failed to continue cherry-pick after resolving conflicts: %v", err)
+				}
+				state.conflicts++
+			} else {
+				return fmt.Errorf("cherry-pick failed: %v", err)
 			}
-			return fmt.Errorf("cherry-pick failed: %v", err)
 		}
 		fmt.Printf("%s✅ Cherry-pick successful%s\n", common.ColorGreen, common.ColorReset)
+
+		if state.resetAuthor {
+			if err := common.AmendCommitResetAuthor(); err != nil {
+				return fmt.Errorf("failed to reset author: %v", err)
+			}
+		}
+
+		if state.showStat {
+			if err := common.ShowStat("HEAD"); err != nil {
+				fmt.Printf("%sWarning: Failed to show commit stat: %v%s\n", common.ColorYellow, err, common.ColorReset)
+			}
+		}
+	}
+	return nil
+}
+
+// parseResolveRule splits a --resolve rule of the form <path>=ours|theirs
+func parseResolveRule(rule string) (string, string, error) {
+	index := strings.LastIndex(rule, "=")
+	if index <= 0 {
+		return "", "", fmt.Errorf("invalid --resolve rule '%s', expected <path>=ours|theirs", rule)
+	}
+
+	path, side := rule[:index], rule[index+1:]
+	if side != "ours" && side != "theirs" {
+		return "", "", fmt.Errorf("invalid --resolve rule '%s', the side must be ours or theirs", rule)
+	}
+	return path, side, nil
+}
+
+// autoResolveConflicts resolves the conflicted files matching a --resolve rule (path or glob).
+// It returns true only if no conflict is left, so unexpected conflicts still stop the reparent.
+func autoResolveConflicts(rules []string) bool {
+	if len(rules) == 0 {
+		return false
+	}
+
+	files, err := common.ConflictedFiles()
+	if err != nil {
+		return false
+	}
+
+	resolvedAll := true
+	for _, file := range files {
+		resolved := false
+		for _, rule := range rules {
+			path, side, _ := parseResolveRule(rule)
+			if matched, _ := filepath.Match(path, file); !matched && path != file {
+				continue
+			}
+			if err := common.ResolveConflict(file, side); err != nil {
+				fmt.Printf("%sWarning: Could not resolve '%s' with '%s': %v%s\n", common.ColorYellow, file, side, err, common.ColorReset)
+				break
+			}
+			fmt.Printf("%s  Resolved '%s' with '%s'%s\n", common.ColorWhite, file, side, common.ColorReset)
+			resolved = true
+			break
+		}
+		if !resolved {
+			resolvedAll = false
+		}
+	}
+	return resolvedAll
+}
+
+// maxAttemptsWithoutProgress is how many times --keep-going prompts again when the number of
+// conflicted files didn't go down, before falling back to stopping the reparent
+const maxAttemptsWithoutProgress = 3
+
+// waitForConflictResolution lets the user resolve a conflict without leaving the reparent with
+// --keep-going. It returns true once no conflicts are left, and false to stop the reparent as
+// usual: stdin isn't a terminal, the user typed 'stop', or the prompts stopped making progress.
+func waitForConflictResolution(state *reparentState) bool {
+	if !common.IsTerminal(os.Stdin) {
+		return false
+	}
+
+	conflicted, _ := common.ConflictedFiles()
+	attemptsWithoutProgress := 0
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("%s⚠️ Cherry-pick resulted in conflicts in:%s\n", common.ColorYellow, common.ColorReset)
+		for _, file := range conflicted {
+			fmt.Printf("%s  %s%s\n", common.ColorWhite, file, common.ColorReset)
+		}
+		fmt.Printf("%sResolve them and stage the files with 'git add', then press Enter to continue (or type 'stop' to finish later with 'git reparent --continue'): %s", common.ColorWhite, common.ColorReset)
+
+		line, err := reader.ReadString('\n')
+		if err != nil || strings.TrimSpace(line) == "stop" {
+			return false
+		}
+
+		// The cherry-pick may have been continued or skipped by hand, let --continue sort it out
+		if !common.IsCherryPickInProgress() {
+			fmt.Printf("%sThe cherry-pick is no longer in progress%s\n", common.ColorYellow, common.ColorReset)
+			return false
+		}
+
+		if len(state.resolveRules) > 0 {
+			autoResolveConflicts(state.resolveRules)
+		}
+		remaining, _ := common.ConflictedFiles()
+		if len(remaining) == 0 {
+			fmt.Printf("%s✅ Conflicts resolved, continuing the reparent%s\n", common.ColorGreen, common.ColorReset)
+			return true
+		}
+
+		if len(remaining) < len(conflicted) {
+			attemptsWithoutProgress = 0
+		} else if attemptsWithoutProgress++; attemptsWithoutProgress >= maxAttemptsWithoutProgress {
+			fmt.Printf("%sNo conflicts were resolved in the last %d attempts, stopping%s\n", common.ColorYellow, maxAttemptsWithoutProgress, common.ColorReset)
+			return false
+		}
+		conflicted = remaining
+	}
+}
+
+// resetAuthorOfResolvedCommit resets the author of HEAD if a commit was added since the conflict stopped the reparent
+func resetAuthorOfResolvedCommit() error {
+	reparentHead, err := readReparentHead()
+	if err != nil {
+		return err
+	}
+
+	headCommit, err := common.GetCommitHash("HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %v", err)
+	}
+
+	if headCommit == reparentHead {
+		return nil
+	}
+
+	if err := common.AmendCommitResetAuthor(); err != nil {
+		return fmt.Errorf("failed to reset author of resolved commit: %v", err)
 	}
 	return nil
 }
 
-func finishReparent(originalBranch string, noBranch bool) error {
+// defaultSquashMessage concatenates the subjects of the squashed commits
+func defaultSquashMessage(commits []string) string {
+	commitSubjects, _ := common.GetCommitSubjects(commits)
+	var subjects []string
+	for _, commit := range commits {
+		subject, ok := commitSubjects[commit]
+		if !ok {
+			subject = commit[:8]
+		}
+		subjects = append(subjects, subject)
+	}
+
+	if len(subjects) == 1 {
+		return subjects[0]
+	}
+	return subjects[0] + "\n\n" + strings.Join(subjects[1:], "\n")
+}
+
+// squashOntoParent replaces the reparented commits with a single commit on top of the parent
+func squashOntoParent(state *reparentState) error {
+	fmt.Printf("%s▶️ Squashing reparented commits...%s\n", common.ColorYellow, common.ColorReset)
+	if err := common.SoftReset(state.parentCommit); err != nil {
+		return fmt.Errorf("failed to reset to parent: %v", err)
+	}
+
+	if err := common.CreateCommit(state.squashMessage); err != nil {
+		return fmt.Errorf("failed to create squashed commit: %v", err)
+	}
+	fmt.Printf("%s✅ Commits squashed%s\n", common.ColorGreen, common.ColorReset)
+	return nil
+}
+
+func finishReparent(state *reparentState) error {
+	originalBranch := state.originalBranch
+
+	if state.squash {
+		if err := squashOntoParent(state); err != nil {
+			return err
+		}
+	}
+
 	// Get the current HEAD commit (where we are after cherry-picks)
 	newHead, err := common.GetCommitHash("HEAD")
 	if err != nil {
@@ -288,43 +1176,120 @@ func finishReparent(originalBranch string, noBranch bool) error {
 		fmt.Printf("%sWarning: Failed to cleanup reparent state: %v%s\n", common.ColorYellow, err, common.ColorReset)
 	}
 
-	if !noBranch {
+	// The rebase already moved the branch and left it checked out
+	if !state.noBranch && !state.useRebase {
 		fmt.Printf("%s▶️ Moving branch '%s' to new location...%s\n", common.ColorYellow, originalBranch, common.ColorReset)
 		if err := common.MoveBranch(originalBranch, newHead); err != nil {
 			return fmt.Errorf("failed to move branch: %v", err)
 		}
+	}
 
-		fmt.Printf("%s▶️ Checking out branch '%s'...%s\n", common.ColorYellow, originalBranch, common.ColorReset)
-		if err := common.Checkout(originalBranch); err != nil {
+	// Go back to where the reparent was started from when it was run with --branch
+	checkoutTarget := originalBranch
+	if state.returnTo != "" {
+		checkoutTarget = state.returnTo
+	}
+	if !state.noBranch && (!state.useRebase || state.returnTo != "") {
+		fmt.Printf("%s▶️ Checking out branch '%s'...%s\n", common.ColorYellow, checkoutTarget, common.ColorReset)
+		if err := common.Checkout(checkoutTarget); err != nil {
 			return fmt.Errorf("failed to checkout branch: %v", err)
 		}
 	}
 
+	// Tagging comes last, once the state is cleaned up: --abort never has a tag to remove
+	if state.tag != "" {
+		fmt.Printf("%s▶️ Creating tag '%s' at %s...%s\n", common.ColorYellow, state.tag, newHead[:8], common.ColorReset)
+		if err := common.CreateTag(state.tag, newHead, state.tagAnnotated, state.tagMessage); err != nil {
+			return fmt.Errorf("reparent succeeded, but failed to create tag '%s': %v", state.tag, err)
+		}
+	}
+
 	fmt.Printf("%s🎉 Reparent completed successfully!%s\n", common.ColorGreen, common.ColorReset)
+
+	fmt.Println()
+	fmt.Printf("%sReparent Report:%s\n", common.ColorCyan, common.ColorReset)
+	fmt.Printf("%s  Commits moved:      %d%s\n", common.ColorWhite, state.totalCommits, common.ColorReset)
+	fmt.Printf("%s  Conflicts resolved: %d%s\n", common.ColorWhite, state.conflicts, common.ColorReset)
+	if state.tag != "" {
+		fmt.Printf("%s  Tag:                %s%s\n", common.ColorWhite, state.tag, common.ColorReset)
+	}
+	if !state.startTime.IsZero() {
+		fmt.Printf("%s  Elapsed time:       %s%s\n", common.ColorWhite, time.Since(state.startTime).Round(time.Second), common.ColorReset)
+	}
 	return nil
 }
 
 func getCommitsToReparent(opts *reparentOptions) ([]string, error) {
+	if opts.commitsFile != "" {
+		return readCommitsFile(opts.commitsFile)
+	}
+
 	var revRange string
 
+	// Count from the tip of --branch when given, HEAD otherwise
+	tip := opts.tipRef()
 	if opts.fromRef != "" {
-		// Get commits from fromRef to HEAD
+		// Get commits from fromRef to the tip
 		if !common.GitRefExists(opts.fromRef) {
 			return nil, fmt.Errorf("from reference '%s' does not exist", opts.fromRef)
 		}
-		revRange = fmt.Sprintf("%s..HEAD", opts.fromRef)
+		if opts.toRef != "" {
+			if isAncestor, err := common.IsAncestor(opts.fromRef, opts.toRef); err != nil || !isAncestor {
+				return nil, fmt.Errorf("'%s' is not an ancestor of '%s'", opts.fromRef, opts.toRef)
+			}
+		}
+		revRange = fmt.Sprintf("%s..%s", opts.fromRef, tip)
 	} else {
 		// Get last N commits
-		revRange = fmt.Sprintf("HEAD~%d..HEAD", opts.numberOfCommits)
+		revRange = fmt.Sprintf("%s~%d..%s", tip, opts.numberOfCommits, tip)
 	}
 
 	return common.GetCommitRange(revRange, true)
 }
 
+// readCommitsFile reads one reference per line (blank lines and # comments are ignored)
+// and resolves each of them to a commit hash, keeping the order of the file
+func readCommitsFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits file: %v", err)
+	}
+
+	var commits []string
+	for i, line := range strings.Split(string(content), "\n") {
+		ref := strings.TrimSpace(line)
+		if ref == "" || strings.HasPrefix(ref, "#") {
+			continue
+		}
+		commit, err := common.GetCommitHash(ref + "^{commit}")
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: '%s' is not a valid commit", path, i+1, ref)
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
 type reparentState struct {
 	remainingCommits []string
+	currentCommit    string
 	originalBranch   string
+	returnTo         string
 	noBranch         bool
+	showStat         bool
+	resetAuthor      bool
+	totalCommits     int
+	conflicts        int
+	startTime        time.Time
+	squash           bool
+	squashMessage    string
+	parentCommit     string
+	useRebase        bool
+	resolveRules     []string
+	tag              string
+	tagAnnotated     bool
+	tagMessage       string
+	keepGoing        bool
 }
 
 func getReparentStateFile() (string, error) {
@@ -335,16 +1300,34 @@ func getReparentStateFile() (string, error) {
 	return filepath.Join(gitDir, "git-reparent-state"), nil
 }
 
-func saveReparentState(commits []string, originalBranch string, noBranch bool) error {
+func saveReparentState(state *reparentState) error {
 	stateFile, err := getReparentStateFile()
 	if err != nil {
 		return err
 	}
 
-	content := fmt.Sprintf("ORIGINAL_BRANCH=%s\n", originalBranch)
-	content += fmt.Sprintf("NO_BRANCH=%t\n", noBranch)
+	content := fmt.Sprintf("ORIGINAL_BRANCH=%s\n", state.originalBranch)
+	content += fmt.Sprintf("RETURN_TO=%s\n", state.returnTo)
+	content += fmt.Sprintf("NO_BRANCH=%t\n", state.noBranch)
+	content += fmt.Sprintf("SHOW_STAT=%t\n", state.showStat)
+	content += fmt.Sprintf("RESET_AUTHOR=%t\n", state.resetAuthor)
+	content += fmt.Sprintf("TOTAL_COMMITS=%d\n", state.totalCommits)
+	content += fmt.Sprintf("CONFLICTS=%d\n", state.conflicts)
+	content += fmt.Sprintf("START_TIME=%d\n", state.startTime.Unix())
+	content += fmt.Sprintf("PARENT=%s\n", state.parentCommit)
+	content += fmt.Sprintf("SQUASH=%t\n", state.squash)
+	content += fmt.Sprintf("SQUASH_MESSAGE=%s\n", strconv.Quote(state.squashMessage))
+	content += fmt.Sprintf("USE_REBASE=%t\n", state.useRebase)
+	for _, rule := range state.resolveRules {
+		content += fmt.Sprintf("RESOLVE=%s\n", rule)
+	}
+	content += fmt.Sprintf("TAG=%s\n", state.tag)
+	content += fmt.Sprintf("TAG_ANNOTATED=%t\n", state.tagAnnotated)
+	content += fmt.Sprintf("TAG_MESSAGE=%s\n", strconv.Quote(state.tagMessage))
+	content += fmt.Sprintf("KEEP_GOING=%t\n", state.keepGoing)
+	content += fmt.Sprintf("CURRENT=%s\n", state.currentCommit)
 	content += "COMMITS=\n"
-	for _, commit := range commits {
+	for _, commit := range state.remainingCommits {
 		content += fmt.Sprintf("%s\n", commit)
 	}
 
@@ -362,6 +1345,9 @@ func loadReparentState() (*reparentState, error) {
 	}
 
 	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
+		if reparentHeadExists() {
+			return nil, fmt.Errorf("REPARENT_HEAD exists but the reparent state file is missing")
+		}
 		return nil, fmt.Errorf("no reparent in progress")
 	}
 
@@ -377,8 +1363,42 @@ func loadReparentState() (*reparentState, error) {
 	for _, line := range lines {
 		if strings.HasPrefix(line, "ORIGINAL_BRANCH=") {
 			state.originalBranch = strings.TrimPrefix(line, "ORIGINAL_BRANCH=")
+		} else if strings.HasPrefix(line, "RETURN_TO=") {
+			state.returnTo = strings.TrimPrefix(line, "RETURN_TO=")
 		} else if strings.HasPrefix(line, "NO_BRANCH=") {
 			state.noBranch = strings.TrimPrefix(line, "NO_BRANCH=") == "true"
+		} else if strings.HasPrefix(line, "SHOW_STAT=") {
+			state.showStat = strings.TrimPrefix(line, "SHOW_STAT=") == "true"
+		} else if strings.HasPrefix(line, "RESET_AUTHOR=") {
+			state.resetAuthor = strings.TrimPrefix(line, "RESET_AUTHOR=") == "true"
+		} else if strings.HasPrefix(line, "TOTAL_COMMITS=") {
+			state.totalCommits, _ = strconv.Atoi(strings.TrimPrefix(line, "TOTAL_COMMITS="))
+		} else if strings.HasPrefix(line, "CONFLICTS=") {
+			state.conflicts, _ = strconv.Atoi(strings.TrimPrefix(line, "CONFLICTS="))
+		} else if strings.HasPrefix(line, "KEEP_GOING=") {
+			state.keepGoing = strings.TrimPrefix(line, "KEEP_GOING=") == "true"
+		} else if strings.HasPrefix(line, "CURRENT=") {
+			state.currentCommit = strings.TrimPrefix(line, "CURRENT=")
+		} else if strings.HasPrefix(line, "PARENT=") {
+			state.parentCommit = strings.TrimPrefix(line, "PARENT=")
+		} else if strings.HasPrefix(line, "SQUASH=") {
+			state.squash = strings.TrimPrefix(line, "SQUASH=") == "true"
+		} else if strings.HasPrefix(line, "SQUASH_MESSAGE=") {
+			state.squashMessage, _ = strconv.Unquote(strings.TrimPrefix(line, "SQUASH_MESSAGE="))
+		} else if strings.HasPrefix(line, "RESOLVE=") {
+			state.resolveRules = append(state.resolveRules, strings.TrimPrefix(line, "RESOLVE="))
+		} else if strings.HasPrefix(line, "TAG=") {
+			state.tag = strings.TrimPrefix(line, "TAG=")
+		} else if strings.HasPrefix(line, "TAG_ANNOTATED=") {
+			state.tagAnnotated = strings.TrimPrefix(line, "TAG_ANNOTATED=") == "true"
+		} else if strings.HasPrefix(line, "TAG_MESSAGE=") {
+			state.tagMessage, _ = strconv.Unquote(strings.TrimPrefix(line, "TAG_MESSAGE="))
+		} else if strings.HasPrefix(line, "USE_REBASE=") {
+			state.useRebase = strings.TrimPrefix(line, "USE_REBASE=") == "true"
+		} else if strings.HasPrefix(line, "START_TIME=") {
+			if seconds, err := strconv.ParseInt(strings.TrimPrefix(line, "START_TIME="), 10, 64); err == nil {
+				state.startTime = time.Unix(seconds, 0)
+			}
 		} else if line == "COMMITS=" {
 			inCommits = true
 		} else if inCommits && line != "" {
@@ -389,16 +1409,6 @@ func loadReparentState() (*reparentState, error) {
 	return state, nil
 }
 
-func updateReparentState(remainingCommits []string) error {
-	state, err := loadReparentState()
-	if err != nil {
-		return err
-	}
-
-	state.remainingCommits = remainingCommits
-	return saveReparentState(state.remainingCommits, state.originalBranch, state.noBranch)
-}
-
 func cleanupReparentState() error {
 	stateFile, err := getReparentStateFile()
 	if err != nil {
@@ -429,6 +1439,19 @@ func createReparentHead() error {
 	return os.WriteFile(reparentHeadFile, []byte(headCommit+"\n"), 0644)
 }
 
+func readReparentHead() (string, error) {
+	gitDir, err := common.GetGitDirectory()
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(filepath.Join(gitDir, "REPARENT_HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read REPARENT_HEAD: %v", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
 func removeReparentHead() error {
 	gitDir, err := common.GetGitDirectory()
 	if err != nil {
@@ -443,7 +1466,13 @@ func removeReparentHead() error {
 	return os.Remove(reparentHeadFile)
 }
 
+// isReparentInProgress checks for either of the reparent markers, so a partially
+// cleaned up operation can still be continued or aborted
 func isReparentInProgress() bool {
+	return reparentHeadExists() || reparentStateFileExists()
+}
+
+func reparentHeadExists() bool {
 	gitDir, err := common.GetGitDirectory()
 	if err != nil {
 		return false
@@ -457,6 +1486,19 @@ func isReparentInProgress() bool {
 	return false
 }
 
+func reparentStateFileExists() bool {
+	stateFile, err := getReparentStateFile()
+	if err != nil {
+		return false
+	}
+
+	if _, err := os.Stat(stateFile); err == nil {
+		return true
+	}
+
+	return false
+}
+
 func printUsage() {
 	fmt.Println("git reparent - Reparent commits to a new parent. This is useful when histories diverges and git rebase")
 	fmt.Println("generates too many conflicts.")
@@ -466,19 +1508,50 @@ func printUsage() {
 	fmt.Println("       git reparent --abort")
 	fmt.Println()
 	fmt.Println("Options:")
-	fmt.Println("  -p, --parent <ref>    New parent reference (required)")
+	fmt.Println("  -p, --parent <ref>    New parent reference (required unless --onto-remote-main, '.' or @{u} for the upstream,")
+	fmt.Println("                        @<bookmark> for what a git bookmark points to)")
+	fmt.Println("  -b, --branch <name>   Reparent commits of this branch instead of the current one")
 	fmt.Println("  -n, --number <num>    Number of commits to reparent (default: 1)")
 	fmt.Println("      --from <ref>      Reparent all commits from <ref> to HEAD")
-	fmt.Println("      --backup          Create a backup before reparenting")
+	fmt.Println("      --to <ref>        End the commits at <ref> instead of HEAD, moving the branch pointing at it")
+	fmt.Println("      --squash          Squash the reparented commits into a single commit")
+	fmt.Println("  -m, --message <msg>   Message of the squashed commit (default: the original subjects)")
+	fmt.Println("      --commits-file <path>  Reparent the commits listed in the file, in order (ignores -n/--from)")
+	fmt.Println("      --backup          Create a backup before reparenting (default: git-tools.auto-backup config)")
+	fmt.Println("      --no-backup       Don't create a backup, even if git-tools.auto-backup is set")
 	fmt.Println("      --confirm         Show summary and ask for confirmation")
+	fmt.Println("      --resolve <path>=ours|theirs  Resolve conflicts on matching files automatically (repeatable, globs allowed)")
+	fmt.Println("                        ours is the new parent's version, theirs the reparented commit's")
+	fmt.Println("      --use-rebase      Move the commits with git rebase --onto instead of cherry-picking them (keeps merges)")
+	fmt.Println("  -y, --yes             With --confirm, show the summary but proceed without asking")
 	fmt.Println("      --no-branch       Don't move the branch, leave it detached")
+	fmt.Println("      --stat            Show a diffstat of each reparented commit")
+	fmt.Println("      --reset-author    Make the current user the author of the reparented commits")
+	fmt.Println("      --auto-main       If a <remote>/<name> parent doesn't exist, use the remote's main branch")
+	fmt.Println("      --tag <name>      Tag the result once the reparent completed (after --continue if needed)")
+	fmt.Println("      --annotated       Create an annotated tag, opening the editor unless --tag-message is given")
+	fmt.Println("      --tag-message <msg>  Message of the annotated tag (implies --annotated)")
+	fmt.Println("      --keep-going      On a conflict the --resolve rules can't handle, wait for it to be resolved instead of stopping")
+	fmt.Println("      --onto-remote-main  Fetch the remote's main branch and use it as the parent (instead of --parent)")
+	fmt.Println("      --remote <name>   Remote for --onto-remote-main (default: git-tools.remote config, or origin)")
+	fmt.Println("      --preview-conflicts  Only report which commits would conflict, trying them in a temporary worktree")
+	fmt.Println("      --pull            Fetch the parent first, fast-forwarding it if it's a local branch tracking a remote")
 	fmt.Println("      --continue        Continue after resolving conflicts")
-	fmt.Println("      --abort           Abort the reparent and return to original branch")
+	fmt.Println("      --force-continue  Continue with the reparent's remaining commits even if git's cherry-pick state differs")
+	fmt.Println("      --abort           Abort the reparent and return to original branch, discarding conflict resolutions")
 	fmt.Println("  -h, --help            Show this help message")
+	fmt.Println("      --version         Show the version of the tool and git")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  git reparent -p origin/main                    # Reparent last commit to origin/main")
 	fmt.Println("  git reparent -p main -n 3                      # Reparent last 3 commits to main")
+	fmt.Println("  git reparent -p @{u}                           # Reparent last commit to the upstream")
+	fmt.Println("  git reparent --onto-remote-main --from main    # Reparent the branch onto the latest origin main")
+	fmt.Println("  git reparent -p @base -n 2                     # Reparent last 2 commits to bookmark 'base'")
 	fmt.Println("  git reparent -p feature-branch --from v1.0     # Reparent all commits since v1.0 to feature-branch")
+	fmt.Println("  git reparent -p main --from v1.0 --to v1.1     # Reparent the commits between two tags")
 	fmt.Println("  git reparent -p main --backup --confirm        # Reparent with backup and confirmation")
+	fmt.Println("  git reparent -p main -n 5 --preview-conflicts  # See which of the last 5 commits would conflict")
+	fmt.Println("  git reparent -p main --pull                    # Update main from its remote, then reparent onto it")
+	fmt.Println("  git reparent -p main -n 3 --squash             # Reparent last 3 commits to main as one commit")
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"git-tools/common"
+)
+
+// parseTestArgs runs parseArgs on a command line, as if git-reparent was called with it
+func parseTestArgs(t *testing.T, cfg *common.Config, args ...string) *reparentOptions {
+	t.Helper()
+	previous := os.Args
+	os.Args = append([]string{"git-reparent"}, args...)
+	t.Cleanup(func() { os.Args = previous })
+
+	opts, err := parseArgs(cfg)
+	if err != nil {
+		t.Fatalf("parseArgs(%v): %v", args, err)
+	}
+	return opts
+}
+
+func TestParseArgsUsesConfigDefaults(t *testing.T) {
+	cfg := &common.Config{Remote: "upstream", BackupPrefix: "backups", AutoBackup: true}
+
+	opts := parseTestArgs(t, cfg, "--onto-remote-main")
+	if !opts.shouldBackup {
+		t.Errorf("git-tools.auto-backup was ignored")
+	}
+	if opts.remote != "upstream" {
+		t.Errorf("remote = %q, want the configured upstream", opts.remote)
+	}
+}
+
+func TestParseArgsFlagsOverrideConfig(t *testing.T) {
+	cfg := &common.Config{Remote: "upstream", BackupPrefix: "backups", AutoBackup: true}
+
+	opts := parseTestArgs(t, cfg, "--onto-remote-main", "--remote", "fork", "--no-backup")
+	if opts.shouldBackup {
+		t.Errorf("--no-backup didn't override git-tools.auto-backup")
+	}
+	if opts.remote != "fork" {
+		t.Errorf("remote = %q, want fork from --remote", opts.remote)
+	}
+
+	cfg.AutoBackup = false
+	if opts := parseTestArgs(t, cfg, "--parent", "main", "--backup"); !opts.shouldBackup {
+		t.Errorf("--backup didn't override git-tools.auto-backup=false")
+	}
+}
@@ -3,29 +3,100 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"git-tools/common"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "--version" {
+		common.PrintVersion("git-split")
+		os.Exit(0)
+	}
+
 	if !common.IsGitRepository() {
 		fmt.Fprintf(os.Stderr, "%sError: This directory is not a git repository.%s\n", common.ColorRed, common.ColorReset)
 		os.Exit(1)
 	}
 
-	var shouldBackup, shouldForce, shouldCommit, shouldNoAdd bool
-	var commitMessage string
+	if common.IsBareRepository() {
+		fmt.Fprintf(os.Stderr, "%sError: git split requires a work tree and cannot run in a bare repository.%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "--abort" {
+		if len(os.Args) > 2 {
+			fmt.Fprintf(os.Stderr, "%sError: --abort doesn't take other arguments%s\n", common.ColorRed, common.ColorReset)
+			os.Exit(1)
+		}
+		headBefore, _ := common.GetCommitHash("HEAD")
+		err := handleAbort()
+		headAfter, _ := common.GetCommitHash("HEAD")
+		common.LogOperation("split", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore, After: headAfter})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cfg, err := common.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+
+	var shouldForce, shouldCommit, shouldNoAdd, shouldShow, shouldSkipCleanCheck bool
+	var commitMessage, amendMessage, forwardMessage, intoRef string
+	var shouldAmendEdit, shouldForward bool
+	shouldBackup := cfg.AutoBackup
 
 	for i := 1; i < len(os.Args); i++ {
 		arg := os.Args[i]
 		switch arg {
 		case "-b", "--backup":
 			shouldBackup = true
+		case "--no-backup":
+			shouldBackup = false
 		case "-f", "--force":
 			shouldForce = true
 		case "--no-add":
 			shouldNoAdd = true
+		case "--no-verify-clean":
+			shouldSkipCleanCheck = true
 		case "-c", "--commit":
 			shouldCommit = true
+		case "--into":
+			if i+1 < len(os.Args) {
+				i++
+				intoRef = os.Args[i]
+			} else {
+				fmt.Fprintf(os.Stderr, "%sError: --into requires a value%s\n", common.ColorRed, common.ColorReset)
+				os.Exit(1)
+			}
+		case "--show":
+			shouldShow = true
+		case "--amend-edit":
+			shouldAmendEdit = true
+		case "--forward":
+			shouldForward = true
+		case "--forward-message":
+			if i+1 < len(os.Args) {
+				i++
+				forwardMessage = os.Args[i]
+				shouldForward = true // The message is for the forward commit
+			} else {
+				fmt.Fprintf(os.Stderr, "%sError: --forward-message requires a value%s\n", common.ColorRed, common.ColorReset)
+				os.Exit(1)
+			}
+		case "--amend-message":
+			if i+1 < len(os.Args) {
+				i++
+				amendMessage = os.Args[i]
+			} else {
+				fmt.Fprintf(os.Stderr, "%sError: --amend-message requires a value%s\n", common.ColorRed, common.ColorReset)
+				os.Exit(1)
+			}
 		case "-m", "--message":
 			if i+1 < len(os.Args) {
 				i++
@@ -45,7 +116,32 @@ func main() {
 		}
 	}
 
+	// Amending in the middle of another operation is unsafe
+	if operation := inProgressOperation(); operation != "" {
+		fmt.Fprintf(os.Stderr, "%sError: A %s is in progress. Finish or abort it before running git split.%s\n", common.ColorRed, operation, common.ColorReset)
+		if operation == "split" {
+			fmt.Fprintf(os.Stderr, "%sA previous git split was interrupted, use 'git split --abort' to restore the state before it%s\n", common.ColorYellow, common.ColorReset)
+		}
+		os.Exit(1)
+	}
+
 	// Check for parameter incompatibilities
+	if amendMessage != "" && shouldAmendEdit {
+		fmt.Fprintf(os.Stderr, "%sError: --amend-message is incompatible with --amend-edit%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
+	if shouldForward && (amendMessage != "" || shouldAmendEdit) {
+		fmt.Fprintf(os.Stderr, "%sError: --forward is incompatible with --amend-message and --amend-edit%s\n", common.ColorRed, common.ColorReset)
+		fmt.Fprintf(os.Stderr, "%s--forward leaves the previous commit alone, there is nothing to amend%s\n", common.ColorYellow, common.ColorReset)
+		os.Exit(1)
+	}
+
+	if intoRef != "" && (shouldForward || amendMessage != "" || shouldAmendEdit) {
+		fmt.Fprintf(os.Stderr, "%sError: --into is incompatible with --forward, --amend-message and --amend-edit%s\n", common.ColorRed, common.ColorReset)
+		os.Exit(1)
+	}
+
 	if shouldNoAdd && shouldCommit {
 		fmt.Fprintf(os.Stderr, "%sError: --no-add is incompatible with --commit and --message%s\n", common.ColorRed, common.ColorReset)
 		fmt.Fprintf(os.Stderr, "%s--no-add skips staging changes, but --commit/--message requires staged changes to commit%s\n", common.ColorYellow, common.ColorReset)
@@ -70,10 +166,17 @@ func main() {
 			fmt.Fprintf(os.Stderr, "%sError: Could not check for unstaged changes: %s%s\n", common.ColorRed, err, common.ColorReset)
 			os.Exit(1)
 		}
-		if hasUnstaged {
-			fmt.Fprintf(os.Stderr, "%sError: There are unstaged changes. Use --force to proceed anyway or stage your changes first.%s\n", common.ColorRed, common.ColorReset)
+		if hasUnstaged && !shouldSkipCleanCheck {
+			fmt.Fprintf(os.Stderr, "%sError: There are unstaged changes. Use --force or --no-verify-clean to proceed anyway or stage your changes first.%s\n", common.ColorRed, common.ColorReset)
 			os.Exit(1)
 		}
+		// Unlike --force, the remainder is still staged, unstaged changes included
+		if hasUnstaged && !shouldNoAdd {
+			fmt.Printf("%sWarning: --no-verify-clean: your unstaged changes will be staged along with the split%s\n", common.ColorYellow, common.ColorReset)
+			if shouldCommit {
+				fmt.Printf("%s         and included in the new commit%s\n", common.ColorYellow, common.ColorReset)
+			}
+		}
 	}
 
 	hasStaged, err := common.HasStagedChanges()
@@ -86,57 +189,130 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Check the older commit before touching anything, a failed rebase is undone but costs time
+	var intoCommit string
+	var intoDepth int
+	if intoRef != "" {
+		intoCommit, intoDepth, err = checkIntoTarget(intoRef)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%sError: %s%s\n", common.ColorRed, err, common.ColorReset)
+			os.Exit(1)
+		}
+	}
+
 	fmt.Printf("%s📝 Git Split Process Starting...%s\n", common.ColorCyan, common.ColorReset)
 
 	if shouldBackup {
 		fmt.Printf("%s▶️ Creating backup...%s\n", common.ColorYellow, common.ColorReset)
-		if err := common.RunGitBackup(); err != nil {
+		if err := common.RunGitBackup("split"); err != nil {
 			fmt.Fprintf(os.Stderr, "%s❌ Failed to create backup: %s%s\n", common.ColorRed, err, common.ColorReset)
 			os.Exit(1)
 		}
 		fmt.Printf("%s✅ Backup created successfully%s\n", common.ColorGreen, common.ColorReset)
 	}
 
+	// Binary changes are kept in the diff with --binary, list them so the restore can be checked
+	binaryFiles, err := common.StagedBinaryFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sWarning: Could not check for binary files: %s%s\n", common.ColorYellow, err, common.ColorReset)
+	} else if len(binaryFiles) > 0 {
+		fmt.Printf("%s⚠️ The split includes binary files:%s\n", common.ColorYellow, common.ColorReset)
+		for _, file := range binaryFiles {
+			fmt.Printf("%s  - %s%s\n", common.ColorWhite, file, common.ColorReset)
+		}
+	}
+
+	// The reverse diff restores these files, --no-add suggests staging them afterwards
+	splitFiles, err := common.StagedFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%sError: Could not list the staged files: %s%s\n", common.ColorRed, err, common.ColorReset)
+		os.Exit(1)
+	}
+
 	// Create diff file in .git directory
 	gitDir, err := common.GetGitDirectory()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%sError: Could not determine git directory: %s%s\n", common.ColorRed, err, common.ColorReset)
 		os.Exit(1)
 	}
-	diffFile := gitDir + "/git-split.diff"
+	// Suffix the diff file with our PID so overlapping invocations don't collide
+	diffFile := filepath.Join(gitDir, fmt.Sprintf("git-split-%d.diff", os.Getpid()))
 	fmt.Printf("%s▶️ Creating diff file: %s%s\n", common.ColorYellow, diffFile, common.ColorReset)
 	if err := common.CreateStagedDiff(diffFile); err != nil {
 		fmt.Fprintf(os.Stderr, "%s❌ Failed to create diff file: %s%s\n", common.ColorRed, err, common.ColorReset)
-		os.Exit(1)
+		exitRemovingDiff(diffFile)
 	}
 
-	// Ensure cleanup happens even if something fails
-	defer func() {
-		if err := os.Remove(diffFile); err != nil && !os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "%sWarning: Could not remove diff file: %s%s\n", common.ColorYellow, err, common.ColorReset)
-		}
-	}()
+	// Failures below exit through exitRemovingDiff, os.Exit skips deferred calls
+	defer removeDiffFile(diffFile)
 
-	fmt.Printf("%s▶️ Amending previous commit...%s\n", common.ColorYellow, common.ColorReset)
-	if err := common.AmendCommit(); err != nil {
-		fmt.Fprintf(os.Stderr, "%s❌ Failed to amend commit: %s%s\n", common.ColorRed, err, common.ColorReset)
-		os.Exit(1)
+	headBefore, err := common.GetCommitHash("HEAD")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s❌ Failed to get the current commit: %s%s\n", common.ColorRed, err, common.ColorReset)
+		exitRemovingDiff(diffFile)
+	}
+
+	// Record the state before the split, so --abort can restore it if a step fails
+	if err := saveSplitState(headBefore); err != nil {
+		fmt.Fprintf(os.Stderr, "%s❌ Failed to save the split state: %s%s\n", common.ColorRed, err, common.ColorReset)
+		exitRemovingDiff(diffFile)
+	}
+
+	if shouldForward {
+		// Split forward: the staged content becomes a new commit on top of the previous one
+		fmt.Printf("%s▶️ Committing staged content as a new commit...%s\n", common.ColorYellow, common.ColorReset)
+		if err := common.CreateCommit(forwardMessage); err != nil {
+			common.LogOperation("split", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore})
+			cleanupSplitState()
+			fmt.Fprintf(os.Stderr, "%s❌ Failed to commit staged content: %s%s\n", common.ColorRed, err, common.ColorReset)
+			exitRemovingDiff(diffFile)
+		}
+		fmt.Printf("%s✅ Staged content committed successfully%s\n", common.ColorGreen, common.ColorReset)
+	} else if intoCommit != "" {
+		fmt.Printf("%s▶️ Amending commit %s...%s\n", common.ColorYellow, intoCommit[:8], common.ColorReset)
+		stashed, err := amendOlderCommit(intoCommit, headBefore)
+		if err != nil {
+			common.LogOperation("split", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore})
+			cleanupSplitState()
+			fmt.Fprintf(os.Stderr, "%s❌ Failed to amend commit %s: %s%s\n", common.ColorRed, intoCommit[:8], err, common.ColorReset)
+			exitRemovingDiff(diffFile)
+		}
+		if stashed {
+			if err := common.StashPop(); err != nil {
+				common.LogOperation("split", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore})
+				fmt.Fprintf(os.Stderr, "%s❌ Failed to restore the unstaged changes, they are kept in the stash: %s%s\n", common.ColorRed, err, common.ColorReset)
+				fmt.Fprintf(os.Stderr, "%sRun 'git split --abort' to restore the state before the split%s\n", common.ColorYellow, common.ColorReset)
+				exitRemovingDiff(diffFile)
+			}
+		}
+		fmt.Printf("%s✅ Commit amended successfully%s\n", common.ColorGreen, common.ColorReset)
+	} else {
+		fmt.Printf("%s▶️ Amending previous commit...%s\n", common.ColorYellow, common.ColorReset)
+		if err := common.AmendCommit(amendMessage, shouldAmendEdit); err != nil {
+			common.LogOperation("split", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore})
+			cleanupSplitState()
+			fmt.Fprintf(os.Stderr, "%s❌ Failed to amend commit: %s%s\n", common.ColorRed, err, common.ColorReset)
+			exitRemovingDiff(diffFile)
+		}
+		fmt.Printf("%s✅ Commit amended successfully%s\n", common.ColorGreen, common.ColorReset)
 	}
-	fmt.Printf("%s✅ Commit amended successfully%s\n", common.ColorGreen, common.ColorReset)
 
 	fmt.Printf("%s▶️ Applying reverse diff to restore working directory...%s\n", common.ColorYellow, common.ColorReset)
 	if err := common.ApplyReverseDiff(diffFile); err != nil {
+		common.LogOperation("split", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore})
 		fmt.Fprintf(os.Stderr, "%s❌ Failed to apply reverse diff: %s%s\n", common.ColorRed, err, common.ColorReset)
-		fmt.Fprintf(os.Stderr, "%sWarning: You may need to manually restore your working directory%s\n", common.ColorYellow, common.ColorReset)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "%sRun 'git split --abort' to restore the state before the split%s\n", common.ColorYellow, common.ColorReset)
+		exitRemovingDiff(diffFile)
 	}
 	fmt.Printf("%s✅ Working directory restored%s\n", common.ColorGreen, common.ColorReset)
 
 	if !shouldNoAdd {
 		fmt.Printf("%s▶️ Staging all changes...%s\n", common.ColorYellow, common.ColorReset)
 		if err := common.StageAllChanges(); err != nil {
+			common.LogOperation("split", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore})
 			fmt.Fprintf(os.Stderr, "%s❌ Failed to stage changes: %s%s\n", common.ColorRed, err, common.ColorReset)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "%sRun 'git split --abort' to restore the state before the split%s\n", common.ColorYellow, common.ColorReset)
+			exitRemovingDiff(diffFile)
 		}
 		fmt.Printf("%s✅ All changes staged%s\n", common.ColorGreen, common.ColorReset)
 	} else {
@@ -146,17 +322,32 @@ func main() {
 	if shouldCommit {
 		fmt.Printf("%s▶️ Creating new commit...%s\n", common.ColorYellow, common.ColorReset)
 		if err := common.CreateCommit(commitMessage); err != nil {
+			common.LogOperation("split", os.Args[1:], err, common.RefChange{Ref: "HEAD", Before: headBefore})
 			fmt.Fprintf(os.Stderr, "%s❌ Failed to create commit: %s%s\n", common.ColorRed, err, common.ColorReset)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "%sRun 'git split --abort' to restore the state before the split%s\n", common.ColorYellow, common.ColorReset)
+			exitRemovingDiff(diffFile)
 		}
 		fmt.Printf("%s✅ New commit created%s\n", common.ColorGreen, common.ColorReset)
 	}
 
+	if err := cleanupSplitState(); err != nil {
+		fmt.Printf("%sWarning: Failed to remove the split state: %v%s\n", common.ColorYellow, err, common.ColorReset)
+	}
+
 	fmt.Printf("%s🎉 Git split process completed successfully!%s\n", common.ColorGreen, common.ColorReset)
+	headAfter, _ := common.GetCommitHash("HEAD")
+	common.LogOperation("split", os.Args[1:], nil, common.RefChange{Ref: "HEAD", Before: headBefore, After: headAfter})
 	
 	fmt.Println()
 	fmt.Printf("%sSplit Summary:%s\n", common.ColorCyan, common.ColorReset)
-	fmt.Printf("%s  Previous commit: Amended%s\n", common.ColorWhite, common.ColorReset)
+	if shouldForward {
+		fmt.Printf("%s  Previous commit: Unchanged%s\n", common.ColorWhite, common.ColorReset)
+		fmt.Printf("%s  Staged content:  Committed on top (--forward)%s\n", common.ColorWhite, common.ColorReset)
+	} else if intoCommit != "" {
+		fmt.Printf("%s  Older commit:    Amended, %d commit(s) after it rebased%s\n", common.ColorWhite, intoDepth, common.ColorReset)
+	} else {
+		fmt.Printf("%s  Previous commit: Amended%s\n", common.ColorWhite, common.ColorReset)
+	}
 	fmt.Printf("%s  Working dir:     Restored%s\n", common.ColorWhite, common.ColorReset)
 	if !shouldNoAdd {
 		fmt.Printf("%s  Changes:         Staged%s\n", common.ColorWhite, common.ColorReset)
@@ -175,6 +366,358 @@ func main() {
 	} else {
 		fmt.Printf("%s  New commit:      Not created (use --commit to auto-commit)%s\n", common.ColorWhite, common.ColorReset)
 	}
+
+	if shouldShow {
+		showSplitResult(shouldCommit, shouldForward, intoDepth)
+	}
+
+	if shouldNoAdd && !shouldCommit {
+		printNextStepHint(splitFiles)
+	}
+}
+
+// printNextStepHint prints the command staging and committing the restored files, leaving
+// alone any other unstaged change
+func printNextStepHint(files []string) {
+	quoted := make([]string, len(files))
+	for i, file := range files {
+		quoted[i] = common.ShellQuote(file)
+	}
+
+	fmt.Println()
+	fmt.Printf("%sTo commit the restored changes:%s\n", common.ColorCyan, common.ColorReset)
+	fmt.Printf("  git add -- %s && git commit\n", strings.Join(quoted, " "))
+}
+
+// removeDiffFile removes the diff file of this invocation
+func removeDiffFile(diffFile string) {
+	if err := os.Remove(diffFile); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "%sWarning: Could not remove diff file: %s%s\n", common.ColorYellow, err, common.ColorReset)
+	}
+}
+
+// exitRemovingDiff exits with an error once the diff file is removed
+func exitRemovingDiff(diffFile string) {
+	removeDiffFile(diffFile)
+	os.Exit(1)
+}
+
+// showSplitResult prints the diffstat of the amended (or, with --forward, split off) commit and,
+// if one was created, of the new commit. depth is the number of commits after the amended one.
+func showSplitResult(committed, forward bool, depth int) {
+	if committed {
+		depth++
+	}
+	amendedRef := fmt.Sprintf("HEAD~%d", depth)
+
+	label := "Amended commit"
+	if forward {
+		label = "Split commit"
+	}
+
+	fmt.Println()
+	fmt.Printf("%s%s:%s\n", common.ColorCyan, label, common.ColorReset)
+	if err := common.ShowStat(amendedRef); err != nil {
+		fmt.Fprintf(os.Stderr, "%sWarning: Could not show the %s: %s%s\n", common.ColorYellow, strings.ToLower(label), err, common.ColorReset)
+	}
+
+	if committed {
+		fmt.Println()
+		fmt.Printf("%sNew commit:%s\n", common.ColorCyan, common.ColorReset)
+		if err := common.ShowStat("HEAD"); err != nil {
+			fmt.Fprintf(os.Stderr, "%sWarning: Could not show the new commit: %s%s\n", common.ColorYellow, err, common.ColorReset)
+		}
+	}
+}
+
+// checkIntoTarget checks that --into names a commit the staged content can be folded into with
+// an autosquash rebase, and returns it with the number of commits after it
+func checkIntoTarget(ref string) (string, int, error) {
+	commit, err := common.ResolveCommit(ref)
+	if err != nil {
+		return "", 0, err
+	}
+	head, err := common.GetCommitHash("HEAD")
+	if err != nil {
+		return "", 0, fmt.Errorf("could not get the current commit: %v", err)
+	}
+	if commit == head {
+		return "", 0, fmt.Errorf("'%s' is HEAD, run git split without --into", ref)
+	}
+	if isAncestor, err := common.IsAncestor(commit, "HEAD"); err != nil || !isAncestor {
+		return "", 0, fmt.Errorf("'%s' is not reachable from HEAD", ref)
+	}
+	if !common.GitRefExists(commit + "^") {
+		return "", 0, fmt.Errorf("'%s' is the root commit, which --into can't rebase", ref)
+	}
+
+	if hasMerges, err := common.HasMergeCommits(commit + "..HEAD"); err != nil || hasMerges {
+		return "", 0, fmt.Errorf("there are merge commits after '%s', which the rebase would flatten", ref)
+	}
+	commits, err := common.GetCommitRange(commit+"..HEAD", false)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not list the commits after '%s': %v", ref, err)
+	}
+	// The autosquash rebase would fold these as well
+	subjects, err := common.GetCommitSubjects(commits)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not read the commits after '%s': %v", ref, err)
+	}
+	for _, subject := range subjects {
+		if strings.HasPrefix(subject, "fixup!") || strings.HasPrefix(subject, "squash!") || strings.HasPrefix(subject, "amend!") {
+			return "", 0, fmt.Errorf("'%s' after '%s' would also be squashed, squash it first", subject, ref)
+		}
+	}
+	return commit, len(commits), nil
+}
+
+// amendOlderCommit folds the staged content into an older commit: it is committed as a fixup,
+// the unstaged changes are stashed and an autosquash rebase moves the fixup into place. If any
+// step fails, HEAD, the staged content and the unstaged changes are put back as they were.
+// It returns whether the unstaged changes are in the stash, for the caller to restore them.
+func amendOlderCommit(commit, headBefore string) (bool, error) {
+	if err := common.CreateCommit("fixup! " + commit); err != nil {
+		return false, fmt.Errorf("failed to commit the staged content: %v", err)
+	}
+	fixupCommit, err := common.GetCommitHash("HEAD")
+	if err != nil {
+		if undoErr := undoSteps(headBefore, softResetStep(headBefore)); undoErr != nil {
+			return false, fmt.Errorf("could not get the fixup commit: %v, and could not undo it", err)
+		}
+		return false, fmt.Errorf("could not get the fixup commit: %v", err)
+	}
+
+	stashed, err := common.StashPush("git split --into " + commit[:8])
+	if err != nil {
+		if undoErr := undoSteps(headBefore, softResetStep(headBefore)); undoErr != nil {
+			return false, fmt.Errorf("failed to stash the unstaged changes: %v, and could not undo the fixup commit", err)
+		}
+		return false, fmt.Errorf("failed to stash the unstaged changes: %v", err)
+	}
+
+	undo := func() error {
+		var steps []undoStep
+		if common.IsRebaseInProgress() {
+			steps = append(steps, undoStep{command: "git rebase --abort", run: common.AbortRebase})
+		}
+		steps = append(steps, undoStep{
+			command: "git reset --hard " + fixupCommit,
+			run:     func() error { return common.HardReset(fixupCommit) },
+		}, softResetStep(headBefore))
+		if stashed {
+			steps = append(steps, undoStep{command: "git stash pop", run: common.StashPop})
+		}
+		return undoSteps(headBefore, steps...)
+	}
+
+	if err := common.AutosquashRebase(commit + "^"); err != nil {
+		if undoErr := undo(); undoErr != nil {
+			return false, fmt.Errorf("the rebase failed and could not be undone: %v", err)
+		}
+		return false, fmt.Errorf("the rebase failed and was undone: %v", err)
+	}
+
+	// Folding the fixup earlier must end with the same content, else part of it was dropped
+	treeBefore, _ := common.GetCommitHash(fixupCommit + "^{tree}")
+	treeAfter, _ := common.GetCommitHash("HEAD^{tree}")
+	if treeBefore == "" || treeBefore != treeAfter {
+		if undoErr := undo(); undoErr != nil {
+			return false, fmt.Errorf("the staged content doesn't apply to %s as it is in HEAD, and the rebase could not be undone", commit[:8])
+		}
+		return false, fmt.Errorf("the staged content doesn't apply to %s as it is in HEAD, the rebase was undone", commit[:8])
+	}
+	return stashed, nil
+}
+
+// undoStep is one step of putting things back after a failed --into, with the command that does
+// the same by hand
+type undoStep struct {
+	command string
+	run     func() error
+}
+
+func softResetStep(headBefore string) undoStep {
+	return undoStep{
+		command: "git reset --soft " + headBefore,
+		run:     func() error { return common.SoftReset(headBefore) },
+	}
+}
+
+// undoSteps runs the steps in order. If one fails, it stops and prints the commands left to get
+// back to headBefore by hand, starting with the one that failed.
+func undoSteps(headBefore string, steps ...undoStep) error {
+	for i, step := range steps {
+		if err := step.run(); err != nil {
+			fmt.Fprintf(os.Stderr, "%sWarning: '%s' failed while undoing the split: %s%s\n", common.ColorYellow, step.command, err, common.ColorReset)
+			fmt.Fprintf(os.Stderr, "%sTo get back to the commit before the split (%s), run:%s\n", common.ColorYellow, headBefore[:8], common.ColorReset)
+			for _, left := range steps[i:] {
+				fmt.Fprintf(os.Stderr, "%s  %s%s\n", common.ColorYellow, left.command, common.ColorReset)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// inProgressOperation returns the name of the git operation in progress, if any
+func inProgressOperation() string {
+	if operation := common.InProgressOperation(); operation != common.OperationNone {
+		return string(operation)
+	}
+	if isSplitInProgress() {
+		return "split"
+	}
+	return ""
+}
+
+// splitState is what --abort needs to put things back as they were before the split. SPLIT_HEAD
+// holds the commit before the amend, the state file the index and the work tree as trees.
+type splitState struct {
+	originalBranch string
+	indexTree      string
+	workTree       string
+}
+
+func getSplitStateFile() (string, error) {
+	gitDir, err := common.GetGitDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "git-split-state"), nil
+}
+
+func getSplitHeadFile() (string, error) {
+	gitDir, err := common.GetGitDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "SPLIT_HEAD"), nil
+}
+
+func isSplitInProgress() bool {
+	splitHeadFile, err := getSplitHeadFile()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(splitHeadFile)
+	return err == nil
+}
+
+func saveSplitState(headCommit string) error {
+	indexTree, err := common.WriteIndexTree()
+	if err != nil {
+		return fmt.Errorf("could not save the index: %v", err)
+	}
+	workTree, err := common.SnapshotWorkTree()
+	if err != nil {
+		return fmt.Errorf("could not save the work tree: %v", err)
+	}
+	branch, _ := common.GetCurrentBranch()
+
+	stateFile, err := getSplitStateFile()
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("ORIGINAL_BRANCH=%s\n", branch)
+	content += fmt.Sprintf("INDEX_TREE=%s\n", indexTree)
+	content += fmt.Sprintf("WORK_TREE=%s\n", workTree)
+	if err := os.WriteFile(stateFile, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	splitHeadFile, err := getSplitHeadFile()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(splitHeadFile, []byte(headCommit+"\n"), 0644)
+}
+
+func loadSplitState() (string, *splitState, error) {
+	splitHeadFile, err := getSplitHeadFile()
+	if err != nil {
+		return "", nil, err
+	}
+	content, err := os.ReadFile(splitHeadFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read SPLIT_HEAD: %v", err)
+	}
+	// The marker may have been truncated or edited by hand, and is used to reset the branch
+	splitHead, err := common.ResolveCommit(strings.TrimSpace(string(content)))
+	if err != nil {
+		return "", nil, fmt.Errorf("SPLIT_HEAD doesn't hold a valid commit, restore the branch from the reflog")
+	}
+
+	stateFile, err := getSplitStateFile()
+	if err != nil {
+		return "", nil, err
+	}
+	content, err = os.ReadFile(stateFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("SPLIT_HEAD exists but the split state file is missing")
+	}
+
+	state := &splitState{}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "ORIGINAL_BRANCH=") {
+			state.originalBranch = strings.TrimPrefix(line, "ORIGINAL_BRANCH=")
+		} else if strings.HasPrefix(line, "INDEX_TREE=") {
+			state.indexTree = strings.TrimPrefix(line, "INDEX_TREE=")
+		} else if strings.HasPrefix(line, "WORK_TREE=") {
+			state.workTree = strings.TrimPrefix(line, "WORK_TREE=")
+		}
+	}
+	if state.indexTree == "" || state.workTree == "" {
+		return "", nil, fmt.Errorf("the split state file is incomplete")
+	}
+	return splitHead, state, nil
+}
+
+func cleanupSplitState() error {
+	for _, getFile := range []func() (string, error){getSplitStateFile, getSplitHeadFile} {
+		file, err := getFile()
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleAbort restores the commit, the work tree and the staged changes from before an interrupted split
+func handleAbort() error {
+	fmt.Printf("%s🔄 Aborting git split...%s\n", common.ColorCyan, common.ColorReset)
+
+	if !isSplitInProgress() {
+		return fmt.Errorf("no split in progress")
+	}
+
+	splitHead, state, err := loadSplitState()
+	if err != nil {
+		return err
+	}
+
+	if branch, _ := common.GetCurrentBranch(); branch != state.originalBranch {
+		return fmt.Errorf("the split was started on '%s', check it out before aborting", state.originalBranch)
+	}
+
+	fmt.Printf("%s▶️ Resetting to the commit before the split (%s)...%s\n", common.ColorYellow, splitHead[:8], common.ColorReset)
+	if err := common.SoftReset(splitHead); err != nil {
+		return fmt.Errorf("failed to reset to SPLIT_HEAD: %v", err)
+	}
+
+	fmt.Printf("%s▶️ Restoring the work tree and the staged changes...%s\n", common.ColorYellow, common.ColorReset)
+	if err := common.RestoreTrees(state.workTree, state.indexTree); err != nil {
+		return err
+	}
+
+	if err := cleanupSplitState(); err != nil {
+		fmt.Printf("%sWarning: Failed to remove the split state: %v%s\n", common.ColorYellow, err, common.ColorReset)
+	}
+
+	fmt.Printf("%s✅ Split aborted successfully%s\n", common.ColorGreen, common.ColorReset)
+	return nil
 }
 
 func printUsage() {
@@ -189,10 +732,21 @@ func printUsage() {
 	fmt.Println("Usage: git split [options]")
 	fmt.Println()
 	fmt.Println("Options:")
-	fmt.Println("  --backup              Create a backup before splitting")
+	fmt.Println("  --backup              Create a backup before splitting (default: git-tools.auto-backup config)")
+	fmt.Println("  --no-backup           Don't create a backup, even if git-tools.auto-backup is set")
 	fmt.Println("  --force               Proceed even if there are unstaged changes (implies --no-add)")
+	fmt.Println("  --no-verify-clean     Proceed even if there are unstaged changes, still staging (and with --commit,")
+	fmt.Println("                        committing) them with the split. Restoring may conflict with them")
 	fmt.Println("  --no-add              Skip staging all changes after restoring working directory")
 	fmt.Println("  --commit              Create a new commit after restoring changes")
 	fmt.Println("  -m, --message <msg>   Commit message for the new commit (implies --commit)")
+	fmt.Println("  --amend-message <msg> New message for the amended commit")
+	fmt.Println("  --amend-edit          Open the editor to reword the amended commit")
+	fmt.Println("  --forward             Commit the staged content as a new commit instead of amending the previous one")
+	fmt.Println("  --forward-message <msg>  Message of the --forward commit (implies --forward, default: open the editor)")
+	fmt.Println("  --into <ref>          Amend an older commit instead of the previous one, rebasing the commits after it")
+	fmt.Println("  --show                Show the diffstat of the resulting commit(s) when done")
+	fmt.Println("  --abort               Restore the commit, work tree and staged changes from before an interrupted split")
 	fmt.Println("  -h, --help            Show this help message")
+	fmt.Println("  --version             Show the version of the tool and git")
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"git-tools/common/testutil"
+)
+
+// newSplitRepo creates a repository where "file" is added by the second commit and two commits
+// follow it, and returns that second commit
+func newSplitRepo(t *testing.T) string {
+	t.Helper()
+	testutil.NewRepo(t)
+	testutil.Commit(t, "initial")
+	testutil.WriteFile(t, "file", "one\ntwo\nthree\n")
+	older := testutil.Commit(t, "add file")
+	testutil.WriteFile(t, "other", "other\n")
+	testutil.Commit(t, "add other")
+	testutil.WriteFile(t, "last", "last\n")
+	testutil.Commit(t, "add last")
+	return older
+}
+
+func TestAmendOlderCommitKeepsTree(t *testing.T) {
+	older := newSplitRepo(t)
+	testutil.WriteFile(t, "file", "one\nthree\n")
+	originalTree := testutil.Git(t, "write-tree")
+	// An unstaged change is stashed during the rebase and must come back
+	if err := os.WriteFile("other", []byte("unstaged\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	headBefore := testutil.Git(t, "rev-parse", "HEAD")
+
+	stashed, err := amendOlderCommit(older, headBefore)
+	if err != nil {
+		t.Fatalf("amendOlderCommit: %v", err)
+	}
+	if !stashed {
+		t.Fatalf("the unstaged change wasn't stashed")
+	}
+	testutil.Git(t, "stash", "pop", "-q")
+
+	if tree := testutil.Git(t, "rev-parse", "HEAD^{tree}"); tree != originalTree {
+		t.Errorf("HEAD tree is %s after the split, want the original tree %s", tree, originalTree)
+	}
+	if count := testutil.Git(t, "rev-list", "--count", "HEAD"); count != "4" {
+		t.Errorf("%s commits after the split, want 4", count)
+	}
+	if amended := testutil.Git(t, "show", "HEAD~2:file"); amended != "one\nthree" {
+		t.Errorf("the older commit has file %q, want the staged content", amended)
+	}
+	if content, _ := os.ReadFile("other"); string(content) != "unstaged\n" {
+		t.Errorf("the unstaged change is %q after the split", content)
+	}
+}
+
+func TestAmendOlderCommitUndoesFailedRebase(t *testing.T) {
+	older := newSplitRepo(t)
+	testutil.WriteFile(t, "file", "one\ntwo\nthree\nfour\n")
+	testutil.Commit(t, "add four")
+	// "four" doesn't exist yet in the older commit, so the fixup conflicts there
+	testutil.WriteFile(t, "file", "one\ntwo\nthree\nfive\n")
+	stagedTree := testutil.Git(t, "write-tree")
+	headBefore := testutil.Git(t, "rev-parse", "HEAD")
+
+	if _, err := amendOlderCommit(older, headBefore); err == nil {
+		t.Fatalf("amendOlderCommit succeeded with a fixup that conflicts with the older commit")
+	}
+
+	if head := testutil.Git(t, "rev-parse", "HEAD"); head != headBefore {
+		t.Errorf("HEAD is %s after the failed split, want %s", head, headBefore)
+	}
+	if tree := testutil.Git(t, "write-tree"); tree != stagedTree {
+		t.Errorf("the index is %s after the failed split, want the staged content %s", tree, stagedTree)
+	}
+}